@@ -0,0 +1,108 @@
+package timeparser
+
+import (
+	"sort"
+	"time"
+)
+
+// ==============================================================
+// Comparison
+// ==============================================================
+
+// the absolute instant
+func (data *TimeData) instant() time.Time {
+	return *data.Time()
+}
+
+// the instant of d in the location of data
+func (data *TimeData) instantIn(d *TimeData) time.Time {
+	t := d.instant()
+	if data.loc != nil {
+		return t.In(data.loc)
+	}
+	return t.In(time.Local)
+}
+
+// check if data is before d
+func (data *TimeData) Before(d *TimeData) bool {
+	return data.instant().Before(d.instant())
+}
+
+// check if data is after d
+func (data *TimeData) After(d *TimeData) bool {
+	return data.instant().After(d.instant())
+}
+
+// check if data and d are the same instant (even if their locations are different)
+func (data *TimeData) Equal(d *TimeData) bool {
+	return data.instant().Equal(d.instant())
+}
+
+// -1 if data is before d, 1 if after, 0 if the same instant
+func (data *TimeData) Compare(d *TimeData) int {
+	t1, t2 := data.instant(), d.instant()
+	switch {
+	case t1.Before(t2):
+		return -1
+	case t1.After(t2):
+		return 1
+	}
+	return 0
+}
+
+// check if data is between a and b (the order of a and b doesn't matter)
+func (data *TimeData) Between(a *TimeData, b *TimeData, inclusive bool) bool {
+	if a.After(b) {
+		a, b = b, a
+	}
+	if inclusive {
+		return data.Compare(a) >= 0 && data.Compare(b) <= 0
+	}
+	return data.After(a) && data.Before(b)
+}
+
+// check if d is on the same day as data (in the location of data)
+func (data *TimeData) IsSameDay(d *TimeData) bool {
+	t1, t2 := data.instantIn(data), data.instantIn(d)
+	return t1.Year() == t2.Year() && t1.YearDay() == t2.YearDay()
+}
+
+// check if d is in the same month as data (in the location of data)
+func (data *TimeData) IsSameMonth(d *TimeData) bool {
+	t1, t2 := data.instantIn(data), data.instantIn(d)
+	return t1.Year() == t2.Year() && t1.Month() == t2.Month()
+}
+
+// check if d is in the same year as data (in the location of data)
+func (data *TimeData) IsSameYear(d *TimeData) bool {
+	return data.instantIn(data).Year() == data.instantIn(d).Year()
+}
+
+// the earliest one (nil if no arguments are given)
+func MinTimeData(ds ...*TimeData) *TimeData {
+	var res *TimeData
+	for _, d := range ds {
+		if res == nil || d.Before(res) {
+			res = d
+		}
+	}
+	return res
+}
+
+// the latest one (nil if no arguments are given)
+func MaxTimeData(ds ...*TimeData) *TimeData {
+	var res *TimeData
+	for _, d := range ds {
+		if res == nil || d.After(res) {
+			res = d
+		}
+	}
+	return res
+}
+
+// sort TimeData variables by their instants (the order of the same instants is kept)
+func SortTimeData(ds []*TimeData) {
+	sort.SliceStable(ds, func(i, j int) bool {
+		return ds[i].Before(ds[j])
+	})
+}
@@ -0,0 +1,69 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	d1, _ := New("2021-12-29 18:00:00 +0900")
+	d2, _ := New("2021-12-29 09:00:00 +0000")
+	d3, _ := New("2021-12-29 18:00:00 Asia/Tokyo")
+	d4, _ := New("2021-12-29 10:00:00 +0000")
+
+	// the same instant in different offsets
+	assert.True(t, d1.Equal(d2))
+	assert.True(t, d1.Equal(d3))
+	assert.Equal(t, 0, d2.Compare(d3))
+
+	assert.True(t, d1.Before(d4))
+	assert.False(t, d1.After(d4))
+	assert.True(t, d4.After(d3))
+	assert.Equal(t, -1, d1.Compare(d4))
+	assert.Equal(t, 1, d4.Compare(d1))
+
+	// between
+	d5, _ := New("2021-12-29 09:30:00 +0000")
+	assert.True(t, d5.Between(d1, d4, false))
+	assert.True(t, d5.Between(d4, d1, false))
+	assert.False(t, d1.Between(d2, d4, false))
+	assert.True(t, d1.Between(d2, d4, true))
+
+	// min / max
+	assert.Equal(t, d4, MaxTimeData(d1, d4, d5))
+	assert.Equal(t, d1, MinTimeData(d1, d4, d5))
+	assert.Nil(t, MinTimeData())
+
+	// sort
+	ds := []*TimeData{d4, d1, d5, d2}
+	SortTimeData(ds)
+	assert.Equal(t, []*TimeData{d1, d2, d5, d4}, ds)
+}
+
+func TestIsSame(t *testing.T) {
+	d1, _ := New("2021-12-31 23:00:00 +0000")
+	d2, _ := New("2022-01-01 08:00:00 +0900") // 2021-12-31 23:00 UTC
+	d3, _ := New("2022-01-01 10:00:00 +0900") // 2022-01-01 01:00 UTC
+
+	assert.True(t, d1.IsSameDay(d2))
+	assert.True(t, d1.IsSameMonth(d2))
+	assert.True(t, d1.IsSameYear(d2))
+	assert.False(t, d1.IsSameDay(d3))
+	assert.False(t, d1.IsSameMonth(d3))
+	assert.False(t, d1.IsSameYear(d3))
+
+	// compared in the location of the receiver
+	assert.True(t, d2.IsSameDay(d3))
+	assert.True(t, d2.IsSameYear(d1))
+}
+
+func ExampleSortTimeData() {
+	d1, _ := New("2021-12-29 18:00:00 +0900")
+	d2, _ := New("2021-12-29 10:00:00 +0000")
+	d3, _ := New("2021-12-29 09:30:00 +0000")
+
+	ds := []*TimeData{d2, d1, d3}
+	SortTimeData(ds)
+	fmt.Println(ds[0] == d1, ds[2] == d2) // true true
+}
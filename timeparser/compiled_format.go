@@ -0,0 +1,192 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ==============================================================
+// Compiled Format
+// ==============================================================
+
+// a chunk of a compiled format
+type formatToken struct {
+	chr byte   // format character (0 means literal text)
+	lit string // literal text
+	src string // format string from this token (used in parsing)
+}
+
+// Format is a pre-tokenised format string.
+// It is safe for concurrent use by multiple goroutines.
+//
+// Compilation only speeds up Format and AppendFormat.
+// Parse still interprets each format character like ParseFormat
+// (only the validation and the splitting of literal text are done once).
+type Format struct {
+	layout string
+	tokens []formatToken
+	mode   int // FORMAT_PHP | FORMAT_LEGACY
+}
+
+// characters which have meanings only in parsing
+const parseOnlyChrs = "!|+#?*"
+
+// Compile a format string (same characters as FormatTime)
+//
+// It returns an error if the format has a dangling backslash or an unknown letter
+// (issues of ValidateFormat whose severity is FORMAT_ISSUE_ERROR).
+func CompileFormat(layout string) (*Format, error) {
+	return CompileFormatWithMode(layout, FORMAT_PHP)
+}
+
+// same as CompileFormat but format characters follow mode (FORMAT_PHP or FORMAT_LEGACY)
+func CompileFormatWithMode(layout string, mode int) (*Format, error) {
+	if err := formatError(ValidateFormatWithMode(layout, mode)); err != nil {
+		return nil, err
+	}
+
+	f := Format{layout, make([]formatToken, 0, len(layout)), mode}
+
+	lit := make([]byte, 0, len(layout))
+	l_len := len(layout)
+	for i := 0; i < l_len; i++ {
+		c := layout[i]
+
+		// backslash
+		if c == '\\' {
+			lit = append(lit, layout[i+1])
+			i++
+			continue
+		}
+
+		if !isFormatChr(c) && strings.IndexByte(parseOnlyChrs, c) < 0 {
+			lit = append(lit, c)
+			continue
+		}
+
+		if len(lit) > 0 {
+			f.tokens = append(f.tokens, formatToken{0, string(lit), ""})
+			lit = lit[:0]
+		}
+		f.tokens = append(f.tokens, formatToken{c, "", layout[i:]})
+	}
+	if len(lit) > 0 {
+		f.tokens = append(f.tokens, formatToken{0, string(lit), ""})
+	}
+	return &f, nil
+}
+
+// same as CompileFormat but panics if the format cannot be compiled
+func MustCompileFormat(layout string) *Format {
+	f, err := CompileFormat(layout)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// the format string
+func (f *Format) String() string {
+	return f.layout
+}
+
+// Append a formatted string to dst
+func (f *Format) AppendFormat(dst []byte, t time.Time) []byte {
+	for i := range f.tokens {
+		if f.tokens[i].chr == 0 {
+			dst = append(dst, f.tokens[i].lit...)
+			continue
+		}
+		var ok bool
+		if dst, ok = appendFormatChr(dst, f.tokens[i].chr, &t, f.mode); !ok {
+			dst = append(dst, f.tokens[i].chr)
+		}
+	}
+	return dst
+}
+
+// Format a time.Time variable to a string
+func (f *Format) Format(t time.Time) string {
+	return string(f.AppendFormat(make([]byte, 0, len(f.layout)+16), t))
+}
+
+// Convert a datetime string to a time.Time variable
+// (unspecified fields are filled with the current time like ParseFormat)
+//
+// It is not faster than ParseFormat except that the format is validated only once.
+func (f *Format) Parse(s string) (*time.Time, error) {
+	return f.ParseWithBase(s, nil)
+}
+
+// Convert a datetime string to a time.Time variable
+// (unspecified fields are filled with base, nil means now)
+func (f *Format) ParseWithBase(s string, base *time.Time) (*time.Time, error) {
+	data, err := f.ParseData(s, base)
+	if err != nil {
+		return nil, err
+	}
+	return data.resolve()
+}
+
+// Convert a datetime string to a TimeData variable
+// (unspecified fields are filled with base, nil means now)
+func (f *Format) ParseData(s string, base *time.Time) (*TimeData, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty data")
+	}
+
+	data := newTimeData()
+
+	s_len := len(s)
+	pos_s := 0
+	skipped := false // an error was skipped by '+'
+loop:
+	for i := range f.tokens {
+		tok := &f.tokens[i]
+
+		// literal
+		if tok.chr == 0 {
+			for j := 0; j < len(tok.lit); j++ {
+				if isSpace(tok.lit[j]) {
+					_ = skipSpaces(&s, &pos_s)
+					continue
+				}
+				if pos_s >= s_len || s[pos_s] != tok.lit[j] {
+					if data.hasFlag(SKIP_ERRORS) {
+						skipped = true
+						break loop
+					}
+					return nil, errors.New(fmt.Sprintf("failed to parse format: %s", string(tok.lit[j])))
+				}
+				pos_s++
+			}
+			continue
+		}
+
+		if pos_s >= s_len && strings.IndexByte(noInputChrs, tok.chr) < 0 {
+			if data.hasFlag(SKIP_ERRORS) {
+				skipped = true
+				break
+			}
+			return nil, errors.New(fmt.Sprintf("failed to parse format: %s", string(tok.chr)))
+		}
+
+		src := tok.src
+		pos := 0
+		if _, err := parseFormatChar(&src, &pos, &s, &pos_s, data); err != nil {
+			if data.hasFlag(SKIP_ERRORS) {
+				skipped = true
+				break
+			}
+			return nil, err
+		}
+	}
+
+	if _, err := finishParseFormat(data, s, pos_s, 0, base, nil, skipped); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,154 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompileFormat(t *testing.T) {
+	utc, err := time.LoadLocation("UTC")
+	assert.Nil(t, err)
+
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, utc)
+
+	// same as FormatTime
+	layouts := []string{
+		"r",
+		"c",
+		"l jS \\o\\f F Y h:i:s A",
+		"Y-m-d H:i:s",
+		"n/j/y",
+		"D l w N W",
+		"F m M n t",
+		"Y y L o",
+		"a A B",
+		"g G h H",
+		"i s u v",
+		"Z P O",
+		"e T U",
+		"[Y/m/d] (H:i) #?*+!|",
+	}
+	for _, layout := range layouts {
+		f, err := CompileFormat(layout)
+		assert.Nil(t, err, layout)
+		assert.Equal(t, FormatTime(layout, &tm), f.Format(tm), layout)
+		assert.Equal(t, "> "+FormatTime(layout, &tm), string(f.AppendFormat([]byte("> "), tm)), layout)
+		assert.Equal(t, layout, f.String())
+	}
+
+	// errors
+	errorcases := []string{
+		"Y-m-d\\",
+		"l jS \\of F Y", // unknown letter (f)
+	}
+	for _, layout := range errorcases {
+		_, err := CompileFormat(layout)
+		assert.NotNil(t, err, layout)
+	}
+	assert.Panics(t, func() { MustCompileFormat("Y-m-d\\") })
+}
+
+func TestFormatAppendFormatAllocs(t *testing.T) {
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, time.UTC)
+	buf := make([]byte, 0, 128)
+
+	for _, layout := range []string{"c", "r", "Y-m-d H:i:s", "l jS \\o\\f F Y h:i:s A"} {
+		f := MustCompileFormat(layout)
+		allocs := testing.AllocsPerRun(100, func() {
+			buf = f.AppendFormat(buf[:0], tm)
+		})
+		assert.Equal(t, float64(0), allocs, layout)
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	expected := time.Date(2021, time.December, 29, 18, 24, 36, 0, time.Local)
+	testcases := map[string]string{
+		" l jS \\o\\f F Y  h:i:s A": "Wednesday 29th of December  2021 06:24:36 PM ",
+		"  Y-m-d    H:i:s ":         " 2021-12-29  18:24:36  ",
+		"Y#n#j H:i:s":               "2021,12,29 18:24:36",
+	}
+	for layout, s := range testcases {
+		f, err := CompileFormat(layout)
+		assert.Nil(t, err, layout)
+
+		tm, err := f.Parse(s)
+		assert.Nil(t, err, layout)
+		assert.Equal(t, expected.Year(), tm.Year())
+		assert.Equal(t, expected.Month(), tm.Month())
+		assert.Equal(t, expected.Day(), tm.Day())
+		assert.Equal(t, expected.Hour(), tm.Hour())
+		assert.Equal(t, expected.Minute(), tm.Minute())
+		assert.Equal(t, expected.Second(), tm.Second())
+		assert.Equal(t, expected.Location(), tm.Location())
+	}
+
+	// |+
+	f := MustCompileFormat("Y#n#j|+j")
+	tm, err := f.Parse("2021/12/29 ___")
+	assert.Nil(t, err)
+	assert.Equal(t, 29, tm.Day())
+	assert.Equal(t, 0, tm.Hour())
+
+	// same results as ParseFormatWithBase
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+	parsecases := map[string]string{
+		"Y-m-d":       "2021-02-30",
+		"Y-m-d h:i A": "2021-12-29 12:24 AM",
+		"Y z":         "2020 59",
+		"H:i e":       "02:30 America/New_York",
+		"!d":          "15",
+	}
+	for layout, s := range parsecases {
+		tm, err := MustCompileFormat(layout).ParseWithBase(s, &base)
+		assert.Nil(t, err, layout)
+		tm2, err := ParseFormatWithBase(layout, s, &base)
+		assert.Nil(t, err, layout)
+		assert.Equal(t, tm2.String(), tm.String(), layout)
+	}
+
+	// errors
+	f = MustCompileFormat("Y-m-d")
+	_, err = f.Parse("2021/12/29")
+	assert.NotNil(t, err)
+	_, err = f.Parse("2021-12")
+	assert.NotNil(t, err)
+}
+
+func TestFormatConcurrency(t *testing.T) {
+	f := MustCompileFormat("Y-m-d\\TH:i:s.uP")
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456000, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s := f.Format(tm)
+				assert.Equal(t, "2021-12-29T18:24:36.123456+00:00", s)
+				_, err := f.Parse("2021-12-29T18:24:36.123456+00:00")
+				assert.Nil(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func ExampleCompileFormat() {
+	f, err := CompileFormat("Y-m-d H:i:s")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(f.Format(time.Now())) // 2021-12-29 18:24:00
+
+	buf := make([]byte, 0, 64)
+	buf = f.AppendFormat(buf, time.Now())
+	fmt.Println(string(buf)) // 2021-12-29 18:24:00
+
+	tm, err := f.Parse("2021-12-29 18:24:00")
+	fmt.Println(tm, err) // 2021-12-29 18:24:00 +0900 JST <nil>
+}
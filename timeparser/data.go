@@ -1,6 +1,10 @@
 package timeparser
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 //import "fmt"
 
@@ -20,6 +24,10 @@ const (
 	SET_TIMEZONE_LOCATION = 256
 	SET_AP                = 512
 	SKIP_ERRORS           = 1024
+	RESET_FIELDS          = 2048
+	SET_WEEKDAY           = 4096
+	STRICT                = 8192
+	PREFIX                = 16384
 )
 
 // ============================================================
@@ -41,14 +49,15 @@ type timeAddition struct {
 	weekday int    // number of weekday
 	word    string // year | month|day
 	day_flg string // empty | first | last (day of)
+	ago     bool   // already negated by "ago"
 }
 
 func newTimeAddition(n int, unit string) *timeAddition {
-	a := timeAddition{n, unit, -1, -1, -1, -1, "", -1, -1, "", ""}
+	a := timeAddition{n, unit, -1, -1, -1, -1, "", -1, -1, "", "", false}
 	return &a
 }
 func newTimeAdditionWithTime(n int, unit string, h int, i int, s int, us int) *timeAddition {
-	a := timeAddition{n, unit, h, i, s, us, "", -1, -1, "", ""}
+	a := timeAddition{n, unit, h, i, s, us, "", -1, -1, "", "", false}
 	return &a
 }
 
@@ -57,25 +66,31 @@ func newTimeAdditionWithTime(n int, unit string, h int, i int, s int, us int) *t
 // ============================================================
 
 type TimeData struct {
-	y         int            // Year
-	m         int            // Month
-	d         int            // Date
-	h         int            // Hour
-	i         int            // Minute
-	s         int            // Second
-	ns        int            // Nanosecond
-	ap        int            // AM/PM flag (1=AM 2=PM)
-	day       int            // Weekday (actually doesn't affect the result)
-	z         int            // Timezone Offset
-	loc       *time.Location // Timezone Location
-	additions []timeAddition // Relative differences
+	y         int             // Year
+	m         int             // Month
+	d         int             // Date
+	h         int             // Hour
+	i         int             // Minute
+	s         int             // Second
+	ns        int             // Nanosecond
+	ap        int             // AM/PM flag (1=AM 2=PM)
+	day       int             // Weekday (actually doesn't affect the result)
+	z         int             // Timezone Offset (specified as a number like "+09:00". loc is the fixed zone of it)
+	loc       *time.Location  // Timezone Location
+	zone      string          // Timezone text in the parsed string
+	additions []timeAddition  // Relative differences
+	cal       *FiscalCalendar // Fiscal calendar used in parsing and GetFiscalYear (nil means the default)
+	fold      bool            // the later one of ambiguous wall clocks (set by AddAbsolute and AddWallClock)
+	weekStart time.Weekday    // the first day of weeks (StartOf("week"), GetWeekOfMonth)
+
+	disambiguation int // DISAMBIGUATE_* policy of Time()
 
 	flags int // flags
 }
 
 // create a new TimeData variable of 1970/01/01
 func newTimeData() *TimeData {
-	d := TimeData{1970, 1, 1, 0, 0, 0, 0, 0, 0, 0, nil, make([]timeAddition, 0), 0}
+	d := TimeData{1970, 1, 1, 0, 0, 0, 0, 0, 0, 0, nil, "", make([]timeAddition, 0), nil, false, DEFAULT_WEEK_START, DISAMBIGUATE_COMPATIBLE, 0}
 	return &d
 }
 
@@ -126,7 +141,73 @@ func (data *TimeData) reset() {
 	data.z = 0
 	data.loc = nil
 
-	data.flags &= (^SET_YEAR & ^SET_MONTH & ^SET_DAY & ^SET_HOUR & ^SET_MINUTE & ^SET_SECOND & ^SET_NANOSECOND & ^SET_TIMEZONE_OFFSET & ^SET_TIMEZONE_LOCATION & ^SET_AP)
+	data.flags &= (^SET_YEAR & ^SET_MONTH & ^SET_DAY & ^SET_HOUR & ^SET_MINUTE & ^SET_SECOND & ^SET_NANOSECOND & ^SET_TIMEZONE_OFFSET & ^SET_TIMEZONE_LOCATION & ^SET_AP & ^SET_WEEKDAY)
+}
+
+// fill unspecified fields with base like PHP's DateTime::createFromFormat()
+// (nothing is filled after '!' or '|')
+func (data *TimeData) fillUnset(base *time.Time) {
+	if data.hasFlag(RESET_FIELDS) {
+		return
+	}
+	if base == nil {
+		t_ := time.Now()
+		base = &t_
+	}
+	if !data.HasZone() {
+		data.loc = base.Location()
+	} else if data.loc != nil {
+		t_ := base.In(data.loc)
+		base = &t_
+	}
+
+	if !data.hasFlag(SET_YEAR) {
+		data.y = base.Year()
+	}
+	if !data.hasFlag(SET_MONTH) {
+		data.m = int(base.Month())
+	}
+	if !data.hasFlag(SET_DAY) {
+		data.d = base.Day()
+	}
+	// other time fields are 0 if any of them is specified
+	if !data.HasTime() {
+		data.h = base.Hour()
+		data.i = base.Minute()
+		data.s = base.Second()
+		data.ns = base.Nanosecond()
+	}
+}
+
+// fill unspecified leading date fields with base like Moment.js and ICU
+// ("MM-DD" is in the year of base, "YYYY" is January 1st and times are 00:00:00 if not specified)
+//
+// extra is fields resolved later from other fields (day of year, quarters and week dates).
+func (data *TimeData) fillUnsetDate(base *time.Time, extra int) {
+	if base == nil {
+		t_ := time.Now()
+		base = &t_
+	}
+	if !data.HasZone() {
+		data.loc = base.Location()
+	} else if data.loc != nil {
+		t_ := base.In(data.loc)
+		base = &t_
+	}
+
+	f := data.flags | extra
+	if f&SET_YEAR != 0 {
+		return
+	}
+	data.y = base.Year()
+	if f&SET_MONTH != 0 {
+		return
+	}
+	data.m = int(base.Month())
+	if f&SET_DAY != 0 {
+		return
+	}
+	data.d = base.Day()
 }
 
 // ============================================================
@@ -135,7 +216,7 @@ func (data *TimeData) reset() {
 
 // create a new TimeData variable from string format
 func New(format string) (*TimeData, error) {
-	data, err := parseTimeStr(format, nil)
+	data, _, err := parseTimeStr(format, nil, nil, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +239,15 @@ func Now() *TimeData {
 	return data
 }
 
+// create a copy of the TimeData variable
+// (the copy doesn't share the relative additions with the original)
+func (data *TimeData) Clone() *TimeData {
+	d := *data
+	d.additions = make([]timeAddition, len(data.additions))
+	copy(d.additions, data.additions)
+	return &d
+}
+
 // ============================================================
 // setter
 // ============================================================
@@ -194,29 +284,40 @@ func (data *TimeData) setNanosecond(ns int) {
 	data.ns = ns
 	data.flags |= SET_NANOSECOND
 }
+func (data *TimeData) setWeekday(w int) {
+	data.day = w
+	data.flags |= SET_WEEKDAY
+}
+func (data *TimeData) setTime(h int, i int, s int, ns int) {
+	data.setHour(h)
+	data.setMinute(i)
+	data.setSecond(s)
+	data.setNanosecond(ns)
+}
 func (data *TimeData) setTimezoneOffset(z int) {
 	data.z = z
 	data.flags |= SET_TIMEZONE_OFFSET
 }
 func (data *TimeData) setLocation(loc *time.Location) {
 	data.loc = loc
+	data.zone = ""
 	data.flags |= SET_TIMEZONE_LOCATION
 }
-func (data *TimeData) setUTC() {
-	_, offset_ := data.Time().Zone()
-
-	utc, _ := time.LoadLocation("UTC")
-	data.setLocation(utc)
-	if data.z != 0 {
-		z := data.z
 
-		data.setTimezoneOffset(0)
-		data.AddSecond(-z)
+// set the fixed zone of the offset (UTC if z is 0)
+func (data *TimeData) setFixedZone(z int) {
+	if z == 0 {
+		data.setLocation(time.UTC)
+	} else {
+		data.setLocation(time.FixedZone("", z))
 	}
-	if offset_ != 0 {
-		data.AddSecond(-offset_)
-	}
-
+	data.setTimezoneOffset(z)
+}
+func (data *TimeData) setUTC() {
+	t := data.Time().In(time.UTC)
+	data.setWallClock(&t)
+	data.setLocation(time.UTC)
+	data.setTimezoneOffset(0)
 }
 
 func (data *TimeData) setFromTime(t *time.Time) {
@@ -231,6 +332,7 @@ func (data *TimeData) setFromTime(t *time.Time) {
 
 	//_, offset_ := base.Zone()
 	data.setTimezoneOffset(0)
+	data.fold = isLaterWallClock(*t)
 }
 
 func (data *TimeData) setNow() {
@@ -298,12 +400,24 @@ func (data *TimeData) SetNanosecond(ns int) {
 	data.setNanosecond(ns)
 	data.normalize()
 }
+
+// convert to the fixed zone of the offset keeping the instant (like ConvertTo)
 func (data *TimeData) SetTimezoneOffset(z int) {
-	data.setUTC()
-	data.setTimezoneOffset(z)
+	t := data.Time().In(time.FixedZone("", z))
+	data.setWallClock(&t)
+	data.setFixedZone(z)
+}
+
+// set the fixed zone of the offset keeping the wall clock (the instant changes like SetLocation)
+func (data *TimeData) SetTimezoneOffsetKeepWallClock(z int) {
+	data.setFixedZone(z)
 }
+
+// set the location (the wall clock is kept and the fixed offset is forgotten)
 func (data *TimeData) SetLocation(loc *time.Location) {
 	data.setLocation(loc)
+	data.z = 0
+	data.flags &^= SET_TIMEZONE_OFFSET
 }
 func (data *TimeData) SetUTC() {
 	data.setUTC()
@@ -316,18 +430,62 @@ func (data *TimeData) SetNow() {
 	data.setNow()
 }
 
+// set the date of an ISO 8601 week date (d: 1=Monday ... 7=Sunday)
+func (data *TimeData) SetISOWeek(y int, w int, d int) {
+	y_, m_, d_ := isoWeekDate(y, w, d)
+	data.setYear(y_)
+	data.setMonth(m_)
+	data.setDay(d_)
+}
+
+// set the date of the n-th day of the year (1-366)
+func (data *TimeData) SetDayOfYear(n int) {
+	data.setMonth(1)
+	data.setDay(n)
+	data.normalizeYmd()
+}
+
 // this function is same as `SetLocation` but returns the pointer of the TimeData variable
+// (the wall clock is kept, so the instant changes. See ConvertTo)
 func (data *TimeData) In(loc *time.Location) *TimeData {
 	data.SetLocation(loc)
 	return data
 }
 
+// same as In (the wall clock is kept and the instant changes)
+func (data *TimeData) WithLocationKeepWallClock(loc *time.Location) *TimeData {
+	return data.In(loc)
+}
+
+// convert to the location keeping the instant and return the pointer of the TimeData variable
+// (nil means the local timezone)
+func (data *TimeData) ConvertTo(loc *time.Location) *TimeData {
+	if loc == nil {
+		loc = time.Local
+	}
+	t := data.Time().In(loc)
+	data.setWallClock(&t)
+	data.SetLocation(loc)
+	data.fold = isLaterWallClock(t)
+	return data
+}
+
 // this function is same as `SetUTC` but returns the pointer of the TimeData variable
 func (data *TimeData) AsUTC() *TimeData {
 	data.SetUTC()
 	return data
 }
 
+// set the first day of the quarter (00:00:00)
+func (data *TimeData) StartOfQuarter() {
+	_ = data.StartOf(UNIT_QUARTER)
+}
+
+// set the last day of the quarter (23:59:59.999999999)
+func (data *TimeData) EndOfQuarter() {
+	_ = data.EndOf(UNIT_QUARTER)
+}
+
 // ============================================================
 // getter
 // ============================================================
@@ -338,6 +496,9 @@ func (data *TimeData) GetYear() int {
 func (data *TimeData) GetMonth() int {
 	return data.m
 }
+func (data *TimeData) GetQuarter() int {
+	return (data.m-1)/3 + 1
+}
 func (data *TimeData) GetDay() int {
 	return data.d
 }
@@ -359,6 +520,8 @@ func (data *TimeData) GetMicrosecond() int {
 func (data *TimeData) GetNanosecond() int {
 	return data.ns
 }
+
+// the offset specified as a number ("+09:00"). It is 0 if a location is specified (see GetOffset)
 func (data *TimeData) GetTimezoneOffset() int {
 	return data.z
 }
@@ -366,6 +529,96 @@ func (data *TimeData) GetLocation() *time.Location {
 	return data.loc
 }
 
+// the effective offset from UTC in seconds (including DST)
+func (data *TimeData) GetOffset() int {
+	_, offset_ := data.Time().Zone()
+	return offset_
+}
+
+// the timezone text in the parsed string ("+09:00", "Z", "Asia/Tokyo", etc).
+// It is empty if no timezone is specified or the timezone has been changed.
+func (data *TimeData) OriginalZoneText() string {
+	return data.zone
+}
+
+// the date at 00:00 UTC (used to calculate weekdays and weeks)
+func (data *TimeData) date() time.Time {
+	return time.Date(data.y, time.Month(data.m), data.d, 0, 0, 0, 0, time.UTC)
+}
+
+// day of the week (Sunday = 0)
+func (data *TimeData) GetWeekday() time.Weekday {
+	return data.date().Weekday()
+}
+
+// ISO 8601 day of the week (1=Monday ... 7=Sunday)
+func (data *TimeData) GetISOWeekday() int {
+	return (int(data.GetWeekday())+6)%7 + 1
+}
+
+// day of the year (1-366)
+func (data *TimeData) GetDayOfYear() int {
+	return data.date().YearDay()
+}
+
+// ISO 8601 week number (1-53)
+func (data *TimeData) GetISOWeek() int {
+	_, w := data.date().ISOWeek()
+	return w
+}
+
+// ISO 8601 week-numbering year
+func (data *TimeData) GetISOYear() int {
+	y, _ := data.date().ISOWeek()
+	return y
+}
+
+// week of the month (1-6). Weeks start on the day set by TimeData.SetWeekStart
+// and the first week is the one which contains the 1st.
+func (data *TimeData) GetWeekOfMonth() int {
+	first := time.Date(data.y, time.Month(data.m), 1, 0, 0, 0, 0, time.UTC)
+	offset := floorMod(int(first.Weekday())-int(data.weekStart), 7)
+	return (data.d-1+offset)/7 + 1
+}
+
+// number of days in the month
+func (data *TimeData) DaysInMonth() int {
+	return getLastDay(data.y, data.m)
+}
+
+// number of days in the year (365 or 366)
+func (data *TimeData) DaysInYear() int {
+	if data.IsLeapYear() {
+		return 366
+	}
+	return 365
+}
+
+func (data *TimeData) IsLeapYear() bool {
+	return getLastDay(data.y, 2) == 29
+}
+
+// Saturday or Sunday
+func (data *TimeData) IsWeekend() bool {
+	w := data.GetWeekday()
+	return w == time.Saturday || w == time.Sunday
+}
+
+// check if the date is today (in the location of data)
+func (data *TimeData) IsToday() bool {
+	return data.IsSameDay(Now())
+}
+
+// check if the instant is before now
+func (data *TimeData) IsPast() bool {
+	return data.instant().Before(time.Now())
+}
+
+// check if the instant is after now
+func (data *TimeData) IsFuture() bool {
+	return data.instant().After(time.Now())
+}
+
 // ============================================================
 // Add / Sub
 // ============================================================
@@ -376,6 +629,9 @@ func (data *TimeData) AddYear(y int) {
 func (data *TimeData) AddMonth(m int) {
 	data.SetMonth(data.m + m)
 }
+func (data *TimeData) AddQuarter(q int) {
+	data.AddMonth(q * 3)
+}
 func (data *TimeData) AddDay(d int) {
 	data.SetDay(data.d + d)
 }
@@ -407,6 +663,9 @@ func (data *TimeData) SubYear(y int) {
 func (data *TimeData) SubMonth(m int) {
 	data.AddMonth(-m)
 }
+func (data *TimeData) SubQuarter(q int) {
+	data.AddQuarter(-q)
+}
 func (data *TimeData) SubDay(d int) {
 	data.AddDay(-d)
 }
@@ -429,6 +688,176 @@ func (data *TimeData) SubNanosecond(ns int) {
 	data.AddNanosecond(-ns)
 }
 
+// ============================================================
+// Start / End
+// ============================================================
+
+// the first day of weeks unless TimeData.SetWeekStart or ParseRangeWithWeekStart is used
+const DEFAULT_WEEK_START = time.Monday
+
+// Set the first day of weeks used by StartOf("week"), EndOf("week") and GetWeekOfMonth (default: Monday)
+func (data *TimeData) SetWeekStart(w time.Weekday) {
+	data.weekStart = w
+}
+
+// the first day of weeks
+func (data *TimeData) GetWeekStart() time.Weekday {
+	return data.weekStart
+}
+
+// modulo which is always positive
+func floorMod(n int, m int) int {
+	return ((n % m) + m) % m
+}
+
+// truncate fields to the unit (not normalized)
+func (data *TimeData) truncate(unit Unit) error {
+	// date
+	switch unit {
+	case UNIT_CENTURY:
+		// 2001-01-01 .. 2100-12-31 like PHP Carbon
+		data.y -= floorMod(data.y-1, 100)
+		data.m, data.d = 1, 1
+	case UNIT_DECADE:
+		data.y -= floorMod(data.y, 10)
+		data.m, data.d = 1, 1
+	case UNIT_YEAR:
+		data.m, data.d = 1, 1
+	case UNIT_QUARTER:
+		data.m -= (data.m - 1) % 3
+		data.d = 1
+	case UNIT_MONTH:
+		data.d = 1
+	case UNIT_WEEK, UNIT_ISOWEEK:
+		w_ := data.weekStart
+		if unit == UNIT_ISOWEEK {
+			w_ = time.Monday
+		}
+		cur := time.Date(data.y, time.Month(data.m), data.d, 0, 0, 0, 0, time.UTC).Weekday()
+		data.d -= floorMod(int(cur)-int(w_), 7)
+	case UNIT_DAY, UNIT_HOUR, UNIT_MINUTE, UNIT_SECOND:
+	default:
+		return errors.New(fmt.Sprintf("unknown unit: %s", unit))
+	}
+
+	// time
+	switch unit {
+	case UNIT_SECOND:
+		data.ns = 0
+	case UNIT_MINUTE:
+		data.s, data.ns = 0, 0
+	case UNIT_HOUR:
+		data.i, data.s, data.ns = 0, 0, 0
+	default:
+		data.h, data.i, data.s, data.ns = 0, 0, 0, 0
+	}
+	return nil
+}
+
+// move wall clock fields which don't exist in the location (DST gaps) forward like PHP
+func (data *TimeData) fixWallClock() {
+	t := *data.Time()
+	w := time.Date(data.y, time.Month(data.m), data.d, data.h, data.i, data.s, data.ns, time.UTC)
+	w_ := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	if gap := w.Sub(w_); gap > 0 {
+		// time.Date moves back by the gap
+		t = t.Add(gap)
+	}
+	data.setWallClock(&t)
+}
+
+// set wall clock fields without changing flags or the location
+func (data *TimeData) setWallClock(t *time.Time) {
+	data.fold = false
+	data.y, data.m, data.d = t.Year(), int(t.Month()), t.Day()
+	data.h, data.i, data.s, data.ns = t.Hour(), t.Minute(), t.Second(), t.Nanosecond()
+}
+
+// Units of StartOf and EndOf
+type Unit string
+
+const (
+	UNIT_SECOND  Unit = "second"
+	UNIT_MINUTE  Unit = "minute"
+	UNIT_HOUR    Unit = "hour"
+	UNIT_DAY     Unit = "day"
+	UNIT_WEEK    Unit = "week"
+	UNIT_ISOWEEK Unit = "isoweek"
+	UNIT_MONTH   Unit = "month"
+	UNIT_QUARTER Unit = "quarter"
+	UNIT_YEAR    Unit = "year"
+	UNIT_DECADE  Unit = "decade"
+	UNIT_CENTURY Unit = "century"
+)
+
+// Set the beginning of the unit
+//
+// unit is one of the UNIT_* constants (second, minute, hour, day, week, isoweek, month, quarter, year, decade and century).
+// Weeks start on the day set by TimeData.SetWeekStart and ISO weeks start on Monday.
+// If the beginning doesn't exist because of DST, it is the first instant after the gap.
+func (data *TimeData) StartOf(unit Unit) error {
+	if err := data.truncate(unit); err != nil {
+		return err
+	}
+	data.normalize()
+	data.fixWallClock()
+	return nil
+}
+
+// Set the last nanosecond of the unit (same units as StartOf)
+func (data *TimeData) EndOf(unit Unit) error {
+	if err := data.truncate(unit); err != nil {
+		return err
+	}
+	switch unit {
+	case UNIT_CENTURY:
+		data.y += 100
+	case UNIT_DECADE:
+		data.y += 10
+	case UNIT_YEAR:
+		data.y++
+	case UNIT_QUARTER:
+		data.m += 3
+	case UNIT_MONTH:
+		data.m++
+	case UNIT_WEEK, UNIT_ISOWEEK:
+		data.d += 7
+	case UNIT_DAY:
+		data.d++
+	case UNIT_HOUR:
+		data.h++
+	case UNIT_MINUTE:
+		data.i++
+	case UNIT_SECOND:
+		data.s++
+	}
+	data.ns--
+	data.normalize()
+	data.fixWallClock()
+	return nil
+}
+
+// Truncate the wall clock to a multiple of d
+// (multiples are counted from 00:00 if d divides 24 hours)
+func (data *TimeData) TruncateTo(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.Date(data.y, time.Month(data.m), data.d, data.h, data.i, data.s, data.ns, time.UTC).Truncate(d)
+	data.setWallClock(&t)
+	data.fixWallClock()
+}
+
+// Round the wall clock to the nearest multiple of d (halfway values are rounded up)
+func (data *TimeData) RoundTo(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := time.Date(data.y, time.Month(data.m), data.d, data.h, data.i, data.s, data.ns, time.UTC).Round(d)
+	data.setWallClock(&t)
+	data.fixWallClock()
+}
+
 // ============================================================
 // Diff
 // ============================================================
@@ -450,6 +879,9 @@ func (data *TimeData) DiffMonths(d *TimeData) int {
 	}
 	return m_
 }
+func (data *TimeData) DiffQuarters(d *TimeData) int {
+	return int(data.DiffMonths(d) / 3)
+}
 func (data *TimeData) DiffDays(d *TimeData) int {
 	return int(data.DiffSeconds(d) / 86400)
 }
@@ -472,6 +904,15 @@ func (data *TimeData) DiffNanoseconds(d *TimeData) int64 {
 	return data.UnixNano() - d.UnixNano()
 }
 
+// humanized difference from d ("3 days ago", "in 2 hours")
+// d == nil means now
+func (data *TimeData) DiffForHumans(d *TimeData, opts *HumanizeOptions) string {
+	if d == nil {
+		d = Now()
+	}
+	return humanize(*data.Time(), *d.Time(), opts)
+}
+
 // ============================================================
 // flags
 // ============================================================
@@ -479,6 +920,35 @@ func (data *TimeData) hasFlag(f int) bool {
 	return (data.flags & f) == f
 }
 
+// fields specified in the parsed string (SET_YEAR | SET_MONTH | ...)
+func (data *TimeData) SpecifiedFields() int {
+	return data.flags & (SET_YEAR | SET_MONTH | SET_DAY | SET_HOUR | SET_MINUTE | SET_SECOND | SET_NANOSECOND | SET_TIMEZONE_OFFSET | SET_TIMEZONE_LOCATION | SET_AP | SET_WEEKDAY)
+}
+func (data *TimeData) HasYear() bool {
+	return data.hasFlag(SET_YEAR)
+}
+func (data *TimeData) HasMonth() bool {
+	return data.hasFlag(SET_MONTH)
+}
+func (data *TimeData) HasDay() bool {
+	return data.hasFlag(SET_DAY)
+}
+
+// year, month and day are all specified
+func (data *TimeData) HasDate() bool {
+	return data.hasFlag(SET_YEAR | SET_MONTH | SET_DAY)
+}
+
+// any of hour, minute, second or fraction is specified
+func (data *TimeData) HasTime() bool {
+	return data.flags&(SET_HOUR|SET_MINUTE|SET_SECOND|SET_NANOSECOND) != 0
+}
+
+// timezone offset or location is specified
+func (data *TimeData) HasZone() bool {
+	return data.flags&(SET_TIMEZONE_OFFSET|SET_TIMEZONE_LOCATION) != 0
+}
+
 // ============================================================
 // normalize
 // ============================================================
@@ -496,6 +966,8 @@ func (data *TimeData) normalize() {
 
 // normalize Year, Month, Date
 func (data *TimeData) normalizeYmd() {
+	data.fold = false
+
 	// year, month
 	m := data.m - 1
 	data.y, m = norm(data.y, m, 12)
@@ -547,20 +1019,39 @@ func norm(hi, lo, base int) (nhi, nlo int) {
 // ============================================================
 
 // convert to a time.Time variable
+//
+// Wall clocks which don't exist or are ambiguous because of DST
+// are resolved by the policy set by TimeData.SetDisambiguation.
+// Time() can't return an error, so DISAMBIGUATE_REJECT works like DISAMBIGUATE_COMPATIBLE here.
+// Use ResolveTime to get ErrNonexistentTime or ErrAmbiguousTime.
 func (data *TimeData) Time() *time.Time {
+	res, err := data.ResolveTime(data.disambiguation)
+	if err != nil {
+		res, _ = data.ResolveTime(DISAMBIGUATE_COMPATIBLE)
+	}
+	return res
+}
+
+// convert to a time.Time variable with the disambiguation policy
+// (DISAMBIGUATE_REJECT returns ErrNonexistentTime or ErrAmbiguousTime)
+func (data *TimeData) ResolveTime(policy int) (*time.Time, error) {
 	loc := time.Local
 	if data.loc != nil {
 		loc = data.loc
 	}
-	res := time.Date(data.y, time.Month(data.m), data.d, data.h, data.i, data.s, data.ns, loc)
-
-	if data.z != 0 {
-		tmp := res.Unix()
-		tmp += int64(data.z)
-		res = time.Unix(tmp, 0).In(loc)
+	if data.fold {
+		policy = DISAMBIGUATE_LATER
+	}
+	res, err := resolveWallClock(data.wallClock(), loc, policy)
+	if err != nil {
+		return nil, err
 	}
+	return &res, nil
+}
 
-	return &res
+// same as Time() but returns an error with DISAMBIGUATE_REJECT (used by parsers)
+func (data *TimeData) resolve() (*time.Time, error) {
+	return data.ResolveTime(data.disambiguation)
 }
 
 func (data *TimeData) Unix() int64 {
@@ -580,17 +1071,41 @@ func (data *TimeData) UnixNano() int64 {
 // Format
 // ============================================================
 
+// Format with the format characters of FormatTime
+// (E, q and k are also supported if the fiscal calendar is set by SetFiscalCalendar)
 func (data *TimeData) Format(s string) string {
-	return FormatTime(s, data.Time())
+	return string(data.AppendFormat(make([]byte, 0, len(s)+16), s))
+}
+func (data *TimeData) AppendFormat(dst []byte, s string) []byte {
+	return appendFormatWithCalendar(dst, s, data.Time(), data.cal, FORMAT_PHP)
 }
 func (data *TimeData) String() string {
 	return data.Format("c")
 }
+func (data *TimeData) FormatICU(pattern string) string {
+	return FormatICU(pattern, data.Time())
+}
+func (data *TimeData) FormatMoment(format string) string {
+	return FormatMoment(format, data.Time())
+}
 
 // ============================================================
 // Addition
 // ============================================================
 
+// negate relative additions before the last one until the previous "ago"
+func (data *TimeData) negateAdditions() {
+	a_len := len(data.additions)
+	data.additions[a_len-1].ago = true
+	for i := a_len - 2; i >= 0; i-- {
+		a := &data.additions[i]
+		if a.ago || a.unit == "" {
+			break
+		}
+		a.n *= -1
+		a.ago = true
+	}
+}
 func (data *TimeData) processAdditions() {
 	a_len := len(data.additions)
 	for i := 0; i < a_len; i++ {
@@ -604,6 +1119,10 @@ func (data *TimeData) move(a *timeAddition) {
 		data.add(a)
 		return
 	}
+	if a.day_flg != "" && a.weekday < 0 {
+		// avoid overflowing months (first day of next month on January 31)
+		data.d = 1
+	}
 	switch {
 	case a.month > 0:
 		if a.pos == "next" && data.m >= a.month {
@@ -623,8 +1142,17 @@ func (data *TimeData) move(a *timeAddition) {
 			a.n, a.unit = n, "day"
 			data.add(a)
 		}
+	case a.word == "fiscal year":
+		// the first day of the fiscal year
+		cal := getFiscalCalendar(data.cal)
+		n := -1
+		if a.pos == "next" {
+			n = 1
+		}
+		data.y, data.m = cal.periodStart(cal.year(data.y, data.m)+n, 1)
+		data.d = 1
 	case a.word != "":
-		if a.word != "year" && a.word != "month" && a.word != "day" {
+		if a.word != "year" && a.word != "quarter" && a.word != "month" && a.word != "day" {
 			panic("unknown word") // never be occurred
 		}
 		n := -1
@@ -634,46 +1162,68 @@ func (data *TimeData) move(a *timeAddition) {
 		a.n, a.unit = n, a.word
 		data.add(a)
 	}
+	if a.day_flg == "" || a.weekday >= 0 {
+		return
+	}
 
+	// first | last day of
+	data.normalizeYmd()
+	switch {
+	case a.word == "quarter":
+		data.m -= (data.m - 1) % 3
+		if a.day_flg == "last" {
+			data.m += 2
+		}
+	case a.word == "fiscal year" && a.day_flg == "last":
+		data.m += 11
+		data.normalizeYmd()
+	}
+	if a.day_flg == "last" {
+		data.d = getLastDay(data.y, data.m)
+	}
 }
 func (data *TimeData) add(a *timeAddition) {
 	// might be outside of the range
 	// it will be normalized when data is instantiated.
+	// hours, minutes and seconds are elapsed time (see AddAbsolute)
+	var unit time.Duration
 	switch a.unit {
 	case "year":
 		data.y += a.n
+	case "quarter":
+		data.m += a.n * 3
 	case "month":
 		data.m += a.n
 	case "day":
 		data.d += a.n
 	case "hour":
-		data.h += a.n
+		unit = time.Hour
 	case "min":
 		fallthrough
 	case "minute":
-		data.i += a.n
+		unit = time.Minute
 	case "sec":
 		fallthrough
 	case "second":
-		data.s += a.n
+		unit = time.Second
 	case "ms":
 		fallthrough
 	case "msec":
 		fallthrough
 	case "millisecond":
-		data.ns += a.n * 1e6
+		unit = time.Millisecond
 	case "µs":
 		fallthrough
 	case "µsec":
 		fallthrough
 	case "microsecond":
-		data.ns += a.n * 1e3
+		unit = time.Microsecond
 	case "ns":
 		fallthrough
 	case "nsec":
 		fallthrough
 	case "nanosecond":
-		data.ns += a.n
+		unit = time.Nanosecond
 	case "week":
 		data.d += a.n * 7
 	case "forthnight":
@@ -683,7 +1233,11 @@ func (data *TimeData) add(a *timeAddition) {
 	default:
 		panic("unsupported units")
 	}
-	data.normalize()
+	if unit != 0 && a.n != 0 {
+		data.addElapsed(int64(a.n), unit)
+	} else {
+		data.normalize()
+	}
 
 	/*
 		if a.y > 0 {
@@ -695,6 +1249,10 @@ func (data *TimeData) add(a *timeAddition) {
 		if a.d > 0 {
 			data.d = a.d
 		} */
+	if a.h < 0 && a.i < 0 && a.s < 0 && a.ns < 0 {
+		// keep the later one of ambiguous wall clocks
+		return
+	}
 	if a.h >= 0 {
 		data.h = a.h
 	}
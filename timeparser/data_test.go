@@ -213,6 +213,197 @@ func TestDiffMonths(t *testing.T) {
 		assert.Equal(t, v, tdata.DiffMonths(td))
 	}
 }
+func TestQuarter(t *testing.T) {
+	tdata, _ := New("2022-02-15 18:22:33.123456789 +0000")
+	assert.Equal(t, 1, tdata.GetQuarter())
+
+	tdata.AddQuarter(2)
+	assert.Equal(t, 3, tdata.GetQuarter())
+	assert.Equal(t, 8, tdata.GetMonth())
+
+	tdata.SubQuarter(3)
+	assert.Equal(t, 4, tdata.GetQuarter())
+	assert.Equal(t, 2021, tdata.GetYear())
+
+	tdata.StartOfQuarter()
+	assert.Equal(t, "2021-10-01 00:00:00.000000", tdata.Format("Y-m-d H:i:s.u"))
+
+	tdata.EndOfQuarter()
+	assert.Equal(t, "2021-12-31 23:59:59.999999", tdata.Format("Y-m-d H:i:s.u"))
+
+	testcases := map[string]int{
+		"2021-11-15 18:22:33.123456789 +0000": 1,
+		"2021-11-16 18:22:33.123456789 +0000": 0,
+		"2021-05-16 18:22:33.123456789 +0000": 2,
+		"2022-05-15 18:22:33.123456789 +0000": -1,
+		"2022-05-16 18:22:33.123456789 +0000": -1,
+	}
+	base, _ := New("2022-02-15 18:22:33.123456789 +0000")
+	for format, v := range testcases {
+		td, _ := New(format)
+		assert.Equal(t, v, base.DiffQuarters(td), format)
+	}
+
+	// format
+	tm := time.Date(2021, time.March, 31, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2021-Q1", FormatTime("Y-\\QQ", &tm))
+	tm = time.Date(2021, time.December, 29, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2021-Q4", FormatTime("Y-\\QQ", &tm))
+}
+func TestStartOfEndOf(t *testing.T) {
+	testcases := []struct {
+		unit  Unit
+		start string
+		end   string
+	}{
+		{"second", "2021-12-29 18:24:36.000000000", "2021-12-29 18:24:36.999999999"},
+		{"minute", "2021-12-29 18:24:00.000000000", "2021-12-29 18:24:59.999999999"},
+		{"hour", "2021-12-29 18:00:00.000000000", "2021-12-29 18:59:59.999999999"},
+		{"day", "2021-12-29 00:00:00.000000000", "2021-12-29 23:59:59.999999999"},
+		{"week", "2021-12-27 00:00:00.000000000", "2022-01-02 23:59:59.999999999"},
+		{"isoweek", "2021-12-27 00:00:00.000000000", "2022-01-02 23:59:59.999999999"},
+		{"month", "2021-12-01 00:00:00.000000000", "2021-12-31 23:59:59.999999999"},
+		{"quarter", "2021-10-01 00:00:00.000000000", "2021-12-31 23:59:59.999999999"},
+		{"year", "2021-01-01 00:00:00.000000000", "2021-12-31 23:59:59.999999999"},
+		{"decade", "2020-01-01 00:00:00.000000000", "2029-12-31 23:59:59.999999999"},
+		{"century", "2001-01-01 00:00:00.000000000", "2100-12-31 23:59:59.999999999"},
+	}
+	for _, tc := range testcases {
+		tdata, _ := New("2021-12-29 18:24:36.123456789 +0000")
+		assert.Nil(t, tdata.StartOf(tc.unit))
+		assert.Equal(t, tc.start, fmt.Sprintf("%s.%09d", tdata.Format("Y-m-d H:i:s"), tdata.GetNanosecond()), tc.unit)
+
+		tdata, _ = New("2021-12-29 18:24:36.123456789 +0000")
+		assert.Nil(t, tdata.EndOf(tc.unit))
+		assert.Equal(t, tc.end, fmt.Sprintf("%s.%09d", tdata.Format("Y-m-d H:i:s"), tdata.GetNanosecond()), tc.unit)
+	}
+
+	tdata, _ := New("2021-12-29 18:24:36 +0000")
+	assert.NotNil(t, tdata.StartOf("fortnight"))
+	assert.Equal(t, 29, tdata.GetDay())
+
+	// week start
+	assert.Equal(t, time.Monday, tdata.GetWeekStart())
+	tdata.SetWeekStart(time.Sunday)
+	tdata.StartOf(UNIT_WEEK)
+	assert.Equal(t, "2021-12-26", tdata.Format("Y-m-d"))
+	tdata.EndOf("isoweek")
+	assert.Equal(t, "2021-12-26 23:59:59", tdata.Format("Y-m-d H:i:s"))
+}
+func TestStartOfDST(t *testing.T) {
+	// 2018-11-04 00:00 doesn't exist in Sao Paulo
+	saopaulo, err := time.LoadLocation("America/Sao_Paulo")
+	assert.Nil(t, err)
+	tm := time.Date(2018, 11, 4, 12, 0, 0, 0, saopaulo)
+	tdata := newTimeData()
+	tdata.setFromTime(&tm)
+	tdata.StartOf("day")
+	assert.Equal(t, "2018-11-04 01:00:00 -02:00", tdata.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, 1, tdata.GetHour())
+
+	// 2021-11-07 has 25 hours in New York
+	newyork, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	tm = time.Date(2021, 11, 7, 12, 0, 0, 0, newyork)
+	start := newTimeData()
+	start.setFromTime(&tm)
+	start.StartOf("day")
+	end := newTimeData()
+	end.setFromTime(&tm)
+	end.EndOf("day")
+	assert.Equal(t, "2021-11-07 00:00:00 -04:00", start.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, "2021-11-07 23:59:59 -05:00", end.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, int64(25*3600-1), end.DiffSeconds(start))
+}
+func TestTruncateTo(t *testing.T) {
+	tdata, _ := New("2021-12-29 18:24:36.5 +0000")
+	tdata.RoundTo(time.Second)
+	assert.Equal(t, "2021-12-29 18:24:37.000", tdata.Format("Y-m-d H:i:s.v"))
+	tdata.TruncateTo(15 * time.Minute)
+	assert.Equal(t, "2021-12-29 18:15:00", tdata.Format("Y-m-d H:i:s"))
+	tdata.RoundTo(time.Hour)
+	assert.Equal(t, "2021-12-29 18:00:00", tdata.Format("Y-m-d H:i:s"))
+	tdata.RoundTo(24 * time.Hour)
+	assert.Equal(t, "2021-12-30 00:00:00", tdata.Format("Y-m-d H:i:s"))
+
+	// multiples are counted from 00:00 of the wall clock
+	kolkata := time.FixedZone("", 5*3600+1800)
+	tm := time.Date(2021, 12, 29, 18, 24, 36, 0, kolkata)
+	tdata = newTimeData()
+	tdata.setFromTime(&tm)
+	tdata.TruncateTo(time.Hour)
+	assert.Equal(t, "18:00", tdata.Format("H:i"))
+}
+func TestCalendarGetters(t *testing.T) {
+	testcases := []struct {
+		s          string
+		weekday    time.Weekday
+		isoWeekday int
+		dayOfYear  int
+		isoYear    int
+		isoWeek    int
+		weekOfMon  int
+		daysInMon  int
+		daysInYear int
+		weekend    bool
+	}{
+		{"2021-12-29", time.Wednesday, 3, 363, 2021, 52, 5, 31, 365, false},
+		{"2021-12-05", time.Sunday, 7, 339, 2021, 48, 1, 31, 365, true},
+		{"2021-01-03", time.Sunday, 7, 3, 2020, 53, 1, 31, 365, true},
+		{"2024-02-29", time.Thursday, 4, 60, 2024, 9, 5, 29, 366, false},
+		{"2024-12-30", time.Monday, 1, 365, 2025, 1, 6, 31, 366, false},
+	}
+	for _, tc := range testcases {
+		tdata, _ := New(tc.s)
+		assert.Equal(t, tc.weekday, tdata.GetWeekday(), tc.s)
+		assert.Equal(t, tc.isoWeekday, tdata.GetISOWeekday(), tc.s)
+		assert.Equal(t, tc.dayOfYear, tdata.GetDayOfYear(), tc.s)
+		assert.Equal(t, tc.isoYear, tdata.GetISOYear(), tc.s)
+		assert.Equal(t, tc.isoWeek, tdata.GetISOWeek(), tc.s)
+		assert.Equal(t, tc.weekOfMon, tdata.GetWeekOfMonth(), tc.s)
+		assert.Equal(t, tc.daysInMon, tdata.DaysInMonth(), tc.s)
+		assert.Equal(t, tc.daysInYear, tdata.DaysInYear(), tc.s)
+		assert.Equal(t, tc.daysInYear == 366, tdata.IsLeapYear(), tc.s)
+		assert.Equal(t, tc.weekend, tdata.IsWeekend(), tc.s)
+	}
+
+	// week of month with weeks starting on Sunday
+	tdata, _ := New("2021-12-05")
+	assert.Equal(t, 1, tdata.GetWeekOfMonth())
+	tdata.SetWeekStart(time.Sunday)
+	assert.Equal(t, 2, tdata.GetWeekOfMonth())
+
+	// the week start is kept by copies only
+	c := tdata.Clone()
+	assert.Equal(t, time.Sunday, c.GetWeekStart())
+	tdata2, _ := New("2021-12-05")
+	assert.Equal(t, time.Monday, tdata2.GetWeekStart())
+
+	// today / past / future
+	assert.True(t, Now().IsToday())
+	tdata, _ = New("yesterday")
+	assert.False(t, tdata.IsToday())
+	assert.True(t, tdata.IsPast())
+	assert.False(t, tdata.IsFuture())
+	tdata, _ = New("+1 hour")
+	assert.True(t, tdata.IsFuture())
+	assert.False(t, tdata.IsPast())
+}
+func TestCalendarSetters(t *testing.T) {
+	tdata, _ := New("2021-12-29 18:24:00")
+
+	tdata.SetISOWeek(2020, 53, 7)
+	assert.Equal(t, "2021-01-03 18:24:00", tdata.Format("Y-m-d H:i:s"))
+	tdata.SetISOWeek(2025, 1, 1)
+	assert.Equal(t, "2024-12-30", tdata.Format("Y-m-d"))
+
+	tdata.SetDayOfYear(60)
+	assert.Equal(t, "2024-02-29", tdata.Format("Y-m-d"))
+	tdata.SetDayOfYear(367)
+	assert.Equal(t, "2025-01-01", tdata.Format("Y-m-d"))
+	tdata.SetDayOfYear(0)
+	assert.Equal(t, "2024-12-31", tdata.Format("Y-m-d"))
+}
 func TestFormat(t *testing.T) {
 	// Format
 	tdata, _ := New("2022-01-31 18:22:33.123456789 +0000")
@@ -224,13 +415,13 @@ func TestFormat(t *testing.T) {
 		"l jS \\of F Y h:i:s A": "Monday 31st of January 2022 06:22:33 PM",
 		"Y-m-d H:i:s":           "2022-01-31 18:22:33",
 		"n/j/y":                 "1/31/22",
-		"W":                     "5",
+		"W":                     "05",
 
 		// Each format
 		"D l w N":   "Mon Monday 1 1",
 		"F m M n t": "January 01 Jan 1 31",
 		"Y y L o":   "2022 22 0 2022",
-		"a A B":     "pm PM 432",
+		"a A B":     "pm PM 807",
 		"g G h H":   "6 18 06 18",
 		"i s u v":   "22 33 123456 123",
 		"Z P O":     "0 +00:00 +0000",
@@ -243,32 +434,39 @@ func TestFormat(t *testing.T) {
 }
 
 func TestTimezoneOffset(t *testing.T) {
-	// TimeZone Offset always modifiess Location to UTC
+	// TimeZone Offset sets a fixed zone of the offset
 	tdata, _ := New("2022-01-31 11:22:33.123456789 +01:00")
 	utc, _ := time.LoadLocation("UTC")
 
-	assert.Equal(t, utc, tdata.GetLocation())
+	assert.Equal(t, time.FixedZone("", 3600), tdata.GetLocation())
 	assert.Equal(t, 3600, tdata.GetTimezoneOffset())
+	assert.Equal(t, 3600, tdata.GetOffset())
+	assert.Equal(t, "+01:00", tdata.OriginalZoneText())
+	assert.Equal(t, "2022-01-31 11:22:33 +01:00", tdata.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, "2022-01-31T11:22:33+01:00", tdata.String())
+	assert.Equal(t, int64(1643624553), tdata.Unix()) // 2022-01-31 10:22:33 UTC
 
 	tdata, _ = New("2022-01-31 11:22:33.123456789 -0030")
 	assert.Equal(t, -1800, tdata.GetTimezoneOffset())
+	assert.Equal(t, "-0030", tdata.OriginalZoneText())
+	assert.Equal(t, "-00:30", tdata.Format("P"))
 
 	// RFC3339
 	tdata, _ = New("2022-01-31T11:22:33.123456789Z")
 	assert.Equal(t, utc, tdata.GetLocation())
 	assert.Equal(t, 0, tdata.GetTimezoneOffset())
+	assert.Equal(t, "Z", tdata.OriginalZoneText())
 
 	tdata, _ = New("2022-01-31T11:22:33.123456789Z+01:00")
-	assert.Equal(t, utc, tdata.GetLocation())
+	assert.Equal(t, time.FixedZone("", 3600), tdata.GetLocation())
 	assert.Equal(t, 3600, tdata.GetTimezoneOffset())
 
 	tdata, _ = New("2022-01-31T11:22:33.123456789Z-01:00")
-	assert.Equal(t, utc, tdata.GetLocation())
+	assert.Equal(t, time.FixedZone("", -3600), tdata.GetLocation())
 	assert.Equal(t, -3600, tdata.GetTimezoneOffset())
 
 	// When location is set, GetTimeZoneOffset() is always 0.
-	// Note that GetTimeZoneOffset() doesn't mean the offset of the location
-	// and it should be always used with UTC Timezone.
+	// GetOffset() returns the offset of the location.
 	tokyo, _ := time.LoadLocation("Asia/Tokyo")
 
 	var err error
@@ -276,11 +474,31 @@ func TestTimezoneOffset(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, tokyo, tdata.GetLocation())
 	assert.Equal(t, 0, tdata.GetTimezoneOffset())
+	assert.Equal(t, 9*3600, tdata.GetOffset())
+	assert.Equal(t, "Asia/Tokyo", tdata.OriginalZoneText())
 
-	// SetTimezoneOffset() will set not only timezone but also Location to "UTC".
+	// SetLocation() replaces the fixed zone
+	tdata, err = New("2022-01-31 11:22:33 +09:00")
+	assert.Nil(t, err)
+	assert.Equal(t, SET_TIMEZONE_OFFSET, tdata.SpecifiedFields()&SET_TIMEZONE_OFFSET)
+	tdata.SetLocation(tokyo)
+	assert.Equal(t, 0, tdata.SpecifiedFields()&SET_TIMEZONE_OFFSET)
+	assert.True(t, tdata.HasZone())
+	assert.Equal(t, "Asia/Tokyo", tdata.Format("e"))
+
+	// SetTimezoneOffset() converts to a fixed zone and keeps the instant
+	unix := tdata.Unix()
 	tdata.SetTimezoneOffset(-5 * 60 * 60)
-	assert.Equal(t, utc, tdata.GetLocation())
+	assert.Equal(t, time.FixedZone("", -5*60*60), tdata.GetLocation())
 	assert.Equal(t, -5*60*60, tdata.GetTimezoneOffset())
+	assert.Equal(t, "2022-01-30 21:22:33 -05:00", tdata.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, unix, tdata.Unix())
+	assert.Equal(t, "", tdata.OriginalZoneText())
+
+	// SetTimezoneOffsetKeepWallClock() keeps the wall clock like SetLocation()
+	tdata.SetTimezoneOffsetKeepWallClock(3600)
+	assert.Equal(t, 3600, tdata.GetTimezoneOffset())
+	assert.Equal(t, "2022-01-30 21:22:33 +01:00", tdata.Format("Y-m-d H:i:s P"))
 
 	// you can also define UTC TimeData like this
 	tdata, err = NewAsUTC("2022-01-31 11:22:33.123456789 Asia/Tokyo")
@@ -288,6 +506,22 @@ func TestTimezoneOffset(t *testing.T) {
 	assert.Equal(t, utc, tdata.GetLocation())
 	assert.Equal(t, 0, tdata.GetTimezoneOffset())
 	assert.Equal(t, 11-9, tdata.GetHour()) // Asia/Tokyo offset is +9:00
+
+	tdata, err = NewAsUTC("2022-01-31 11:22:33 +09:00")
+	assert.Equal(t, utc, tdata.GetLocation())
+	assert.Equal(t, "2022-01-31 02:22:33", tdata.Format("Y-m-d H:i:s"))
+	tdata, err = NewAsUTC("2022-01-31 01:22:33 +09:00")
+	assert.Equal(t, "2022-01-30 16:22:33", tdata.Format("Y-m-d H:i:s"))
+
+	// other parsers
+	tm, _ := ParseFormat("Y-m-d H:i:s P", "2022-01-31 11:22:33 +09:00")
+	assert.Equal(t, "2022-01-31 11:22:33 +09:00", FormatTime("Y-m-d H:i:s P", tm))
+	tdata, _ = ParseFormatData("Y-m-d H:i:s e", "2022-01-31 11:22:33 Asia/Tokyo")
+	assert.Equal(t, "Asia/Tokyo", tdata.OriginalZoneText())
+	tm, _ = ParseICU("yyyy-MM-dd HH:mm:ssXXX", "2022-01-31 11:22:33+09:00")
+	assert.Equal(t, "2022-01-31 11:22:33 +09:00", FormatTime("Y-m-d H:i:s P", tm))
+	tm, _ = ParseMoment("YYYY-MM-DD HH:mm:ssZ", "2022-01-31 11:22:33+09:00")
+	assert.Equal(t, "2022-01-31 11:22:33 +09:00", FormatTime("Y-m-d H:i:s P", tm))
 }
 
 // ============================================================
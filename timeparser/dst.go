@@ -0,0 +1,241 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ==============================================================
+// DST
+// ==============================================================
+
+// Policies for wall clocks which don't exist (skipped by DST)
+// or are ambiguous (repeated by DST)
+const (
+	DISAMBIGUATE_COMPATIBLE = 0 // the later one of nonexistent times and the earlier one of ambiguous times (like PHP)
+	DISAMBIGUATE_EARLIER    = 1 // the earlier one
+	DISAMBIGUATE_LATER      = 2 // the later one
+	DISAMBIGUATE_REJECT     = 3 // return ErrNonexistentTime or ErrAmbiguousTime
+)
+
+// ErrNonexistentTime is returned (wrapped) with DISAMBIGUATE_REJECT
+// when a wall clock is skipped by DST (e.g. 02:30 on the day DST starts)
+var ErrNonexistentTime = errors.New("nonexistent time")
+
+// ErrAmbiguousTime is returned (wrapped) with DISAMBIGUATE_REJECT
+// when a wall clock is repeated by DST (e.g. 01:30 on the day DST ends)
+var ErrAmbiguousTime = errors.New("ambiguous time")
+
+// Set the disambiguation policy used by TimeData.Time() (default: DISAMBIGUATE_COMPATIBLE)
+//
+// DISAMBIGUATE_REJECT can't be reported by Time() (see ResolveTime).
+func (data *TimeData) SetDisambiguation(policy int) {
+	data.disambiguation = policy
+}
+
+// the disambiguation policy used by TimeData.Time()
+func (data *TimeData) GetDisambiguation() int {
+	return data.disambiguation
+}
+
+// wall clock fields of t in UTC
+func wallClockOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// convert a wall clock (fields in UTC) to the time in loc
+func resolveWallClock(w time.Time, loc *time.Location, policy int) (time.Time, error) {
+	// offsets before and after a transition (if any)
+	_, off1 := w.Add(-24 * time.Hour).In(loc).Zone()
+	_, off2 := w.Add(24 * time.Hour).In(loc).Zone()
+	t1 := w.Add(-time.Duration(off1) * time.Second).In(loc)
+	if off1 == off2 {
+		return t1, nil
+	}
+	t2 := w.Add(-time.Duration(off2) * time.Second).In(loc)
+
+	ok1, ok2 := wallClockOf(t1).Equal(w), wallClockOf(t2).Equal(w)
+	switch {
+	case ok1 && !ok2:
+		return t1, nil
+	case ok2 && !ok1:
+		return t2, nil
+	}
+
+	earlier, later := t1, t2
+	if later.Before(earlier) {
+		earlier, later = later, earlier
+	}
+	if ok1 {
+		// ambiguous
+		switch policy {
+		case DISAMBIGUATE_LATER:
+			return later, nil
+		case DISAMBIGUATE_REJECT:
+			return time.Time{}, fmt.Errorf("%w: %s in %s", ErrAmbiguousTime, w.Format("2006-01-02 15:04:05"), loc)
+		}
+		return earlier, nil
+	}
+
+	// nonexistent
+	switch policy {
+	case DISAMBIGUATE_EARLIER:
+		return earlier, nil
+	case DISAMBIGUATE_REJECT:
+		return time.Time{}, fmt.Errorf("%w: %s in %s", ErrNonexistentTime, w.Format("2006-01-02 15:04:05"), loc)
+	}
+	return later, nil
+}
+
+// check if t is the later one of an ambiguous wall clock
+func isLaterWallClock(t time.Time) bool {
+	earlier, _ := resolveWallClock(wallClockOf(t), t.Location(), DISAMBIGUATE_EARLIER)
+	return !earlier.Equal(t)
+}
+
+// ============================================================
+// TimeData
+// ============================================================
+
+// wall clock fields in UTC
+func (data *TimeData) wallClock() time.Time {
+	return time.Date(data.y, time.Month(data.m), data.d, data.h, data.i, data.s, data.ns, time.UTC)
+}
+
+// Add the elapsed time (the wall clock can change by more or less than d across DST)
+//
+// AddAbsolute(24 * time.Hour) on the day DST starts moves the wall clock by 25 hours
+// while AddDay(1) and AddWallClock(24 * time.Hour, ...) keep it.
+func (data *TimeData) AddAbsolute(d time.Duration) {
+	t := data.Time().Add(d)
+	data.setWallClock(&t)
+	data.fold = isLaterWallClock(t)
+}
+
+// Add n * unit of elapsed time
+//
+// time.Duration overflows for about 292 years,
+// so longer spans are added as whole seconds.
+func (data *TimeData) addElapsed(n int64, unit time.Duration) {
+	if max := int64(math.MaxInt64 / unit); -max <= n && n <= max {
+		data.AddAbsolute(time.Duration(n) * unit)
+		return
+	}
+
+	var sec int64
+	var rem time.Duration
+	if unit >= time.Second {
+		sec = n * int64(unit/time.Second)
+	} else {
+		per := int64(time.Second / unit)
+		sec, rem = n/per, time.Duration(n%per)*unit
+	}
+	t := data.Time()
+	t_ := time.Unix(t.Unix()+sec, int64(t.Nanosecond())).In(t.Location()).Add(rem)
+	data.setWallClock(&t_)
+	data.fold = isLaterWallClock(t_)
+}
+
+// Add d to the wall clock (like AddHour, AddMinute, etc.)
+// and resolve the result with the disambiguation policy.
+//
+// With DISAMBIGUATE_REJECT, data is left unchanged and
+// ErrNonexistentTime or ErrAmbiguousTime is returned if the result is skipped or repeated by DST.
+func (data *TimeData) AddWallClock(d time.Duration, policy int) error {
+	w := data.wallClock().Add(d)
+	loc := time.Local
+	if data.loc != nil {
+		loc = data.loc
+	}
+	t, err := resolveWallClock(w, loc, policy)
+	if err != nil {
+		return err
+	}
+	data.setWallClock(&t)
+	data.fold = isLaterWallClock(t)
+	return nil
+}
+
+// ============================================================
+// Transition
+// ============================================================
+
+// Transition is a change of the offset (or the abbreviation) of a location
+type Transition struct {
+	Time         time.Time // the first instant after the transition (in the location)
+	OffsetBefore int       // offset from UTC in seconds
+	OffsetAfter  int
+	NameBefore   string // abbreviation like "EST"
+	NameAfter    string
+}
+
+// how much wall clocks move (positive when DST starts)
+func (tr Transition) Shift() time.Duration {
+	return time.Duration(tr.OffsetAfter-tr.OffsetBefore) * time.Second
+}
+
+// transitions further than this are not searched
+const transitionSearchLimit = 5 * 366 * 24 * 3600
+
+// zone of the unix time in loc
+func zoneAt(sec int64, loc *time.Location) (string, int) {
+	return time.Unix(sec, 0).In(loc).Zone()
+}
+
+// find the first transition after sec (dir = 1) or the last one at or before sec (dir = -1)
+//
+// Zones are checked every week and the transition is found by binary search,
+// so two transitions within a week can be missed.
+func findTransition(sec int64, loc *time.Location, dir int64) (Transition, bool) {
+	const step = 7 * 24 * 3600
+	name_, offset_ := zoneAt(sec, loc)
+
+	// same zone at `from`, another zone at `to`
+	from, to := sec, sec
+	found := false
+	for n := int64(step); n <= transitionSearchLimit+step && !found; n += step {
+		to = sec + dir*n
+		if name, offset := zoneAt(to, loc); name != name_ || offset != offset_ {
+			found = true
+		} else {
+			from = to
+		}
+	}
+	if !found {
+		return Transition{}, false
+	}
+
+	// binary search
+	for from-to > 1 || to-from > 1 {
+		mid := from + (to-from)/2
+		if name, offset := zoneAt(mid, loc); name == name_ && offset == offset_ {
+			from = mid
+		} else {
+			to = mid
+		}
+	}
+
+	after := from
+	if dir > 0 {
+		after = to
+	}
+	name1, offset1 := zoneAt(after-1, loc)
+	name2, offset2 := zoneAt(after, loc)
+	return Transition{time.Unix(after, 0).In(loc), offset1, offset2, name1, name2}, true
+}
+
+// the next transition of the location after the instant
+// (false if there are no transitions in 5 years)
+func (data *TimeData) NextTransition() (Transition, bool) {
+	t := data.Time()
+	return findTransition(t.Unix(), t.Location(), 1)
+}
+
+// the last transition of the location at or before the instant
+// (false if there are no transitions in 5 years)
+func (data *TimeData) PrevTransition() (Transition, bool) {
+	t := data.Time()
+	return findTransition(t.Unix(), t.Location(), -1)
+}
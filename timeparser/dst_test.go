@@ -0,0 +1,339 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestResolveTime(t *testing.T) {
+	// 2021-03-14 02:00 -> 03:00 (EST -> EDT)
+	// 2021-11-07 02:00 -> 01:00 (EDT -> EST)
+	testcases := []struct {
+		s        string
+		policy   int
+		expected string
+		err      error
+	}{
+		{"2021-03-14 02:30:00 America/New_York", DISAMBIGUATE_COMPATIBLE, "2021-03-14 03:30:00 -04:00", nil},
+		{"2021-03-14 02:30:00 America/New_York", DISAMBIGUATE_LATER, "2021-03-14 03:30:00 -04:00", nil},
+		{"2021-03-14 02:30:00 America/New_York", DISAMBIGUATE_EARLIER, "2021-03-14 01:30:00 -05:00", nil},
+		{"2021-03-14 02:30:00 America/New_York", DISAMBIGUATE_REJECT, "", ErrNonexistentTime},
+		{"2021-11-07 01:30:00 America/New_York", DISAMBIGUATE_COMPATIBLE, "2021-11-07 01:30:00 -04:00", nil},
+		{"2021-11-07 01:30:00 America/New_York", DISAMBIGUATE_EARLIER, "2021-11-07 01:30:00 -04:00", nil},
+		{"2021-11-07 01:30:00 America/New_York", DISAMBIGUATE_LATER, "2021-11-07 01:30:00 -05:00", nil},
+		{"2021-11-07 01:30:00 America/New_York", DISAMBIGUATE_REJECT, "", ErrAmbiguousTime},
+		{"2021-11-07 03:30:00 America/New_York", DISAMBIGUATE_REJECT, "2021-11-07 03:30:00 -05:00", nil},
+		{"2021-11-07 01:30:00 UTC", DISAMBIGUATE_REJECT, "2021-11-07 01:30:00 +00:00", nil},
+	}
+	for _, tc := range testcases {
+		data, _ := New(tc.s)
+		res, err := data.ResolveTime(tc.policy)
+		if tc.err != nil {
+			assert.True(t, errors.Is(err, tc.err), tc.s)
+			assert.Nil(t, res)
+			continue
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, FormatTime("Y-m-d H:i:s P", res), tc.s)
+	}
+}
+
+func TestDisambiguationInParsing(t *testing.T) {
+	tm, err := ParseTimeStr("2021-03-14 02:30:00 America/New_York", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-03-14 03:30:00 -04:00", FormatTime("Y-m-d H:i:s P", tm))
+
+	// policies in parsing (parse to TimeData and resolve it)
+	data, _, err := ParseTimeStrWithReport("2021-03-14 02:30:00 America/New_York", nil)
+	assert.Nil(t, err)
+	_, err = data.ResolveTime(DISAMBIGUATE_REJECT)
+	assert.True(t, errors.Is(err, ErrNonexistentTime))
+	tm, err = data.ResolveTime(DISAMBIGUATE_EARLIER)
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-03-14 01:30:00 -05:00", FormatTime("Y-m-d H:i:s P", tm))
+
+	data, err = ParseICUData("yyyy-MM-dd HH:mm VV", "2021-11-07 01:30 America/New_York", nil)
+	assert.Nil(t, err)
+	_, err = data.ResolveTime(DISAMBIGUATE_REJECT)
+	assert.True(t, errors.Is(err, ErrAmbiguousTime))
+	tm, err = data.ResolveTime(DISAMBIGUATE_LATER)
+	assert.Nil(t, err)
+	assert.Equal(t, "-05:00", FormatTime("P", tm))
+
+	ny, _ := time.LoadLocation("America/New_York")
+	base := time.Date(2021, 1, 1, 0, 0, 0, 0, ny)
+	data, err = ParseMomentData("YYYY-MM-DD HH:mm", "2021-03-14 02:30", &base)
+	assert.Nil(t, err)
+	_, err = data.ResolveTime(DISAMBIGUATE_REJECT)
+	assert.True(t, errors.Is(err, ErrNonexistentTime))
+
+	f, _ := CompileFormat("Y-m-d H:i e")
+	data, err = f.ParseData("2021-03-14 02:30 America/New_York", nil)
+	assert.Nil(t, err)
+	_, err = data.ResolveTime(DISAMBIGUATE_REJECT)
+	assert.True(t, errors.Is(err, ErrNonexistentTime))
+
+	// per value policies
+	data, err = New("2021-03-14 02:30:00 America/New_York")
+	assert.Nil(t, err)
+	_, err = data.ResolveTime(DISAMBIGUATE_REJECT)
+	assert.True(t, errors.Is(err, ErrNonexistentTime))
+
+	data, err = ParseFormatData("Y-m-d H:i e", "2021-11-07 01:30 America/New_York")
+	assert.Nil(t, err)
+	_, err = data.ResolveTime(DISAMBIGUATE_REJECT)
+	assert.True(t, errors.Is(err, ErrAmbiguousTime))
+
+	data, err = ParseFormatData("Y-m-d H:i e", "2021-11-07 03:30 America/New_York")
+	assert.Nil(t, err)
+	_, err = data.ResolveTime(DISAMBIGUATE_REJECT)
+	assert.Nil(t, err)
+
+	// Time() falls back to DISAMBIGUATE_COMPATIBLE
+	data, _ = New("2021-11-07 01:30:00 America/New_York")
+	assert.Equal(t, DISAMBIGUATE_COMPATIBLE, data.GetDisambiguation())
+	assert.Equal(t, "-04:00", FormatTime("P", data.Time()))
+	data.SetDisambiguation(DISAMBIGUATE_REJECT)
+	assert.Equal(t, "-04:00", FormatTime("P", data.Time()))
+
+	data.SetDisambiguation(DISAMBIGUATE_LATER)
+	assert.Equal(t, "-05:00", FormatTime("P", data.Time()))
+	assert.Equal(t, "-05:00", FormatTime("P", data.Clone().Time()))
+
+	// other values are not affected
+	data2, _ := New("2021-11-07 01:30:00 America/New_York")
+	assert.Equal(t, "-04:00", FormatTime("P", data2.Time()))
+}
+
+func TestAddAbsolute(t *testing.T) {
+	// across the start of DST
+	base, _ := New("2021-03-13 12:00:00 America/New_York")
+	data := base.Clone()
+	data2 := base.Clone()
+	data.AddAbsolute(24 * time.Hour)
+	data2.AddDay(1)
+	assert.Equal(t, "2021-03-14 13:00:00 -04:00", data.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, "2021-03-14 12:00:00 -04:00", data2.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, int64(24*3600), data.DiffSeconds(base))
+	assert.Equal(t, int64(23*3600), data2.DiffSeconds(base))
+
+	// across the end of DST (the repeated hour is kept)
+	data, _ = New("2021-11-07 00:30:00 America/New_York")
+	expected := []string{
+		"2021-11-07 01:30:00 -04:00",
+		"2021-11-07 01:30:00 -05:00",
+		"2021-11-07 02:30:00 -05:00",
+	}
+	for _, e := range expected {
+		data.AddAbsolute(time.Hour)
+		assert.Equal(t, e, data.Format("Y-m-d H:i:s P"))
+	}
+	data.AddAbsolute(-2 * time.Hour)
+	assert.Equal(t, "2021-11-07 01:30:00 -04:00", data.Format("Y-m-d H:i:s P"))
+
+	// setters forget the later one
+	data.AddAbsolute(time.Hour)
+	data.SetMinute(45)
+	assert.Equal(t, "2021-11-07 01:45:00 -04:00", data.Format("Y-m-d H:i:s P"))
+}
+
+func TestAddLargeRelativeOffsets(t *testing.T) {
+	// longer than time.Duration
+	base := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	testcases := []struct {
+		s        string
+		expected string
+	}{
+		{"+10000000000 seconds", "2316-11-20 17:46:40"},
+		{"-10000000000 seconds", "1683-02-10 06:13:20"},
+		{"+3000000 hours", "2342-03-29 00:00:00"},
+		{"+200000000 minutes", "2380-04-06 21:20:00"},
+		{"+10000000000000 milliseconds", "2316-11-20 17:46:40"},
+		{"+10000000000000001 microseconds", "2316-11-20 17:46:40"},
+	}
+	for _, tc := range testcases {
+		tm, err := ParseTimeStr(tc.s, &base)
+		assert.Nil(t, err, tc.s)
+		assert.Equal(t, tc.expected, FormatTime("Y-m-d H:i:s", tm), tc.s)
+	}
+	tm, _ := ParseTimeStr("+10000000000000001 microseconds", &base)
+	assert.Equal(t, 1000, tm.Nanosecond())
+}
+
+func TestAddWallClock(t *testing.T) {
+	testcases := []struct {
+		s        string
+		d        time.Duration
+		policy   int
+		expected string
+		err      error
+	}{
+		{"2021-03-13 12:00:00", 24 * time.Hour, DISAMBIGUATE_REJECT, "2021-03-14 12:00:00 -04:00", nil},
+		{"2021-03-14 00:30:00", 2 * time.Hour, DISAMBIGUATE_COMPATIBLE, "2021-03-14 03:30:00 -04:00", nil},
+		{"2021-03-14 00:30:00", 2 * time.Hour, DISAMBIGUATE_EARLIER, "2021-03-14 01:30:00 -05:00", nil},
+		{"2021-03-14 00:30:00", 2 * time.Hour, DISAMBIGUATE_REJECT, "2021-03-14 00:30:00 -05:00", ErrNonexistentTime},
+		{"2021-11-07 00:30:00", time.Hour, DISAMBIGUATE_COMPATIBLE, "2021-11-07 01:30:00 -04:00", nil},
+		{"2021-11-07 00:30:00", time.Hour, DISAMBIGUATE_LATER, "2021-11-07 01:30:00 -05:00", nil},
+		{"2021-11-07 00:30:00", time.Hour, DISAMBIGUATE_REJECT, "2021-11-07 00:30:00 -04:00", ErrAmbiguousTime},
+	}
+	for _, tc := range testcases {
+		data, _ := New(tc.s + " America/New_York")
+		err := data.AddWallClock(tc.d, tc.policy)
+		if tc.err != nil {
+			assert.True(t, errors.Is(err, tc.err), tc.s)
+		} else {
+			assert.Nil(t, err, tc.s)
+		}
+		assert.Equal(t, tc.expected, data.Format("Y-m-d H:i:s P"), tc.s)
+	}
+}
+
+func ExampleTimeData_AddAbsolute() {
+	data, _ := New("2021-03-13 12:00:00 America/New_York")
+	data.AddAbsolute(24 * time.Hour)
+	fmt.Println(data.Format("Y-m-d H:i:s")) // 2021-03-14 13:00:00
+
+	data, _ = New("2021-03-13 12:00:00 America/New_York")
+	_ = data.AddWallClock(24*time.Hour, DISAMBIGUATE_REJECT)
+	fmt.Println(data.Format("Y-m-d H:i:s")) // 2021-03-14 12:00:00
+}
+
+func TestConvertTo(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	newyork, _ := time.LoadLocation("America/New_York")
+
+	data, _ := New("2021-12-29 18:24:00 +09:00")
+	data2 := data.Clone()
+
+	data.ConvertTo(newyork)
+	assert.Equal(t, "2021-12-29 04:24:00 -05:00", data.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, newyork, data.GetLocation())
+	assert.Equal(t, 0, data.GetTimezoneOffset())
+	assert.True(t, data.Equal(data2))
+
+	data.ConvertTo(tokyo)
+	assert.Equal(t, "2021-12-29 18:24:00 +09:00", data.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, tokyo, data.GetLocation())
+
+	// the wall clock is kept
+	data2.WithLocationKeepWallClock(newyork)
+	assert.Equal(t, "2021-12-29 18:24:00 -05:00", data2.Format("Y-m-d H:i:s P"))
+	assert.False(t, data.Equal(data2))
+
+	// the later one of the repeated hour
+	data, _ = New("2021-11-07 06:30:00 UTC")
+	data.ConvertTo(newyork)
+	assert.Equal(t, "2021-11-07 01:30:00 -05:00", data.Format("Y-m-d H:i:s P"))
+}
+
+func TestTransition(t *testing.T) {
+	data, _ := New("2021-12-29 18:24:00 America/New_York")
+
+	tr, ok := data.NextTransition()
+	assert.True(t, ok)
+	assert.Equal(t, "2022-03-13 03:00:00 EDT", FormatTime("Y-m-d H:i:s T", &tr.Time))
+	assert.Equal(t, -5*3600, tr.OffsetBefore)
+	assert.Equal(t, -4*3600, tr.OffsetAfter)
+	assert.Equal(t, "EST", tr.NameBefore)
+	assert.Equal(t, "EDT", tr.NameAfter)
+	assert.Equal(t, time.Hour, tr.Shift())
+
+	tr, ok = data.PrevTransition()
+	assert.True(t, ok)
+	assert.Equal(t, "2021-11-07 01:00:00 EST", FormatTime("Y-m-d H:i:s T", &tr.Time))
+	assert.Equal(t, -time.Hour, tr.Shift())
+
+	// at the transition
+	data, _ = New("2021-11-07 06:00:00 UTC")
+	data.ConvertTo(tr.Time.Location())
+	tr2, ok := data.PrevTransition()
+	assert.True(t, ok)
+	assert.True(t, tr.Time.Equal(tr2.Time))
+	tr2, ok = data.NextTransition()
+	assert.True(t, ok)
+	assert.Equal(t, "2022-03-13 03:00:00 EDT", FormatTime("Y-m-d H:i:s T", &tr2.Time))
+
+	// southern hemisphere
+	data, _ = New("2021-12-29 18:24:00 Australia/Sydney")
+	tr, ok = data.NextTransition()
+	assert.True(t, ok)
+	assert.Equal(t, "2022-04-03 02:00:00 +10:00", FormatTime("Y-m-d H:i:s P", &tr.Time))
+
+	// no transitions
+	for _, s := range []string{"2021-12-29 18:24:00 UTC", "2021-12-29 18:24:00 +09:00", "2021-12-29 18:24:00 Asia/Tokyo"} {
+		data, _ = New(s)
+		_, ok = data.NextTransition()
+		assert.False(t, ok, s)
+		_, ok = data.PrevTransition()
+		assert.False(t, ok, s)
+	}
+}
+
+func ExampleTimeData_NextTransition() {
+	data, _ := New("2021-12-29 18:24:00 America/New_York")
+	if tr, ok := data.NextTransition(); ok {
+		fmt.Println(tr.Time.Format(time.RFC3339), tr.Shift()) // 2022-03-13T03:00:00-04:00 1h0m0s
+	}
+}
+
+func TestRelativeElapsedTime(t *testing.T) {
+	newyork, _ := time.LoadLocation("America/New_York")
+
+	// hours, minutes and seconds are elapsed time and days are calendar days
+	base := time.Date(2021, time.March, 13, 12, 0, 0, 0, newyork)
+	testcases := map[string]string{
+		"+24 hours":         "2021-03-14 13:00:00 -04:00",
+		"+1 day":            "2021-03-14 12:00:00 -04:00",
+		"+1440 minutes":     "2021-03-14 13:00:00 -04:00",
+		"+86400 seconds":    "2021-03-14 13:00:00 -04:00",
+		"+1 day -1 hour":    "2021-03-14 11:00:00 -04:00",
+		"tomorrow +2 hours": "2021-03-14 03:00:00 -04:00",
+		"24 hours ago":      "2021-03-12 12:00:00 -05:00",
+	}
+	for s, expected := range testcases {
+		tm, err := ParseTimeStr(s, &base)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, FormatTime("Y-m-d H:i:s P", tm), s)
+		if s == "+24 hours" {
+			assert.Equal(t, 24*time.Hour, tm.Sub(base))
+		}
+	}
+
+	// across the end of DST
+	base = time.Date(2021, time.November, 7, 0, 30, 0, 0, newyork)
+	tm, _ := ParseTimeStr("+2 hours", &base)
+	assert.Equal(t, "2021-11-07 01:30:00 -05:00", FormatTime("Y-m-d H:i:s P", tm))
+	assert.Equal(t, 2*time.Hour, tm.Sub(base))
+}
+
+func TestSetFromTimeFold(t *testing.T) {
+	newyork, _ := time.LoadLocation("America/New_York")
+
+	// 01:30 EST (the later one)
+	later := time.Date(2021, time.November, 7, 6, 30, 0, 0, time.UTC).In(newyork)
+	data := newTimeData()
+	data.SetFromTime(&later)
+	assert.True(t, later.Equal(*data.Time()))
+	assert.Equal(t, "-05:00", data.Format("P"))
+
+	// 01:30 EDT (the earlier one)
+	earlier := later.Add(-time.Hour)
+	data.SetFromTime(&earlier)
+	assert.True(t, earlier.Equal(*data.Time()))
+
+	// relative times from the later one
+	tm, err := ParseTimeStr("+1 minute", &later)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Minute, tm.Sub(later))
+	tm, _ = ParseTimeStr("-1 minute", &later)
+	assert.Equal(t, -time.Minute, tm.Sub(later))
+	tm, _ = ParseTimeStr("-1 hour", &later)
+	assert.Equal(t, "2021-11-07 01:30:00 -04:00", FormatTime("Y-m-d H:i:s P", tm))
+
+	// specified fields are resolved by the policy
+	tm, _ = ParseTimeStr("01:45", &later)
+	assert.Equal(t, "2021-11-07 01:45:00 -04:00", FormatTime("Y-m-d H:i:s P", tm))
+}
@@ -0,0 +1,199 @@
+package timeparser
+
+import (
+	"strings"
+	"time"
+)
+
+// ==============================================================
+// Find
+// ==============================================================
+
+// FindOptions controls FindAll
+type FindOptions struct {
+	Base          *time.Time // base time of relative formats (nil means now)
+	MinConfidence float64    // matches whose confidence is less than this are dropped (0 means 0.5, negative values keep every match)
+}
+
+// Match is a date or time found in a text
+type Match struct {
+	Start      int       // byte offset of the start
+	End        int       // byte offset of the end (exclusive)
+	Text       string    // matched substring
+	Data       *TimeData // resolved date and time
+	Confidence float64   // 0.0 - 1.0
+}
+
+// default of FindOptions.MinConfidence
+const findMinConfidence = 0.5
+
+// max length of a date in a text
+const findWindow = 80
+
+// words which make a match look like a date
+var findRelativeWords = []string{
+	"next", "last", "ago", "in", "tomorrow", "yesterday", "today", "noon", "midnight",
+}
+
+// words which are skipped at the start of a match
+var findFillerWords = []string{"at", "on", "from"}
+
+// characters which make numbers look like prices or percentages
+const findCurrencyChrs = "$€£¥₩"
+
+// check if the match is a part of a bigger token (version numbers, phone numbers, prices, etc)
+func isFindFalsePositive(text string, start int, end int) bool {
+	// 1.2.3 / 555-123-4567
+	if start >= 2 && strings.IndexByte(".-/:", text[start-1]) >= 0 && isNumeric(text[start-2]) {
+		return true
+	}
+	if end+1 < len(text) && strings.IndexByte(".-/:", text[end]) >= 0 && isNumeric(text[end+1]) {
+		return true
+	}
+	// v1.2.3
+	if start >= 1 && (text[start-1] == 'v' || text[start-1] == 'V') {
+		return true
+	}
+	if start >= 2 && (text[start-2] == 'v' || text[start-2] == 'V') && text[start-1] == '.' {
+		return true
+	}
+	// $2021 / 2021%
+	for _, c := range findCurrencyChrs {
+		if strings.HasSuffix(text[:start], string(c)) {
+			return true
+		}
+	}
+	if end < len(text) && text[end] == '%' {
+		return true
+	}
+
+	// numbers only (1.2.3) need a 4 digit year or a time
+	m := text[start:end]
+	has_alpha := false
+	digits := 0
+	max_digits := 0
+	for i := 0; i < len(m); i++ {
+		switch {
+		case isNumeric(m[i]):
+			digits++
+			if digits > max_digits {
+				max_digits = digits
+			}
+		case ('a' <= m[i] && m[i] <= 'z') || ('A' <= m[i] && m[i] <= 'Z'):
+			has_alpha = true
+			digits = 0
+		default:
+			digits = 0
+		}
+	}
+	if !has_alpha && max_digits < 4 && strings.IndexByte(m, ':') < 0 {
+		return true
+	}
+	return false
+}
+
+// how likely the match is a date or time
+func findConfidence(m string, data *TimeData) float64 {
+	lower := strings.ToLower(strings.TrimSpace(m))
+	if lower == "now" || lower == "just now" {
+		return 0.3
+	}
+
+	c := 0.0
+	switch {
+	case data.HasDate():
+		c = 0.6
+	case data.HasMonth() && data.HasDay():
+		c = 0.5
+	case data.HasMonth() || data.HasYear():
+		c = 0.2
+	}
+	if data.HasTime() {
+		if strings.IndexByte(lower, ':') >= 0 || strings.HasSuffix(lower, "am") || strings.HasSuffix(lower, "pm") {
+			c += 0.5
+		} else {
+			c += 0.3
+		}
+	}
+	if data.hasFlag(SET_WEEKDAY) {
+		c += 0.2
+	}
+	if data.HasZone() {
+		c += 0.1
+	}
+	for _, w := range strings.Fields(lower) {
+		for _, w_ := range findRelativeWords {
+			if w == w_ {
+				c += 0.5
+				break
+			}
+		}
+	}
+	if c > 1.0 {
+		c = 1.0
+	}
+	return c
+}
+
+// Find all dates and times in a text
+//
+// It tries the same formats as ParseTimeStr at the start of each word (except "at", "on" and "from")
+// and drops matches which look like version numbers, phone numbers or prices.
+func FindAll(text string, opts *FindOptions) []Match {
+	o := FindOptions{nil, findMinConfidence}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MinConfidence == 0 {
+		o.MinConfidence = findMinConfidence
+	}
+	base := o.Base
+	if base == nil {
+		t_ := time.Now()
+		base = &t_
+	}
+
+	res := make([]Match, 0)
+
+	t_len := len(text)
+	for i := 0; i < t_len; i++ {
+		// start of a word
+		if !isAlphanumeric(text[i]) || (i > 0 && isAlphanumeric(text[i-1])) {
+			continue
+		}
+
+		// "at 18:00" starts at "18:00"
+		if scanWords(text, i, findFillerWords, true) != nil {
+			continue
+		}
+
+		j := i + findWindow
+		if j > t_len {
+			j = t_len
+		}
+		data, n, err := parseTimeStr(text[i:j], base, nil, PREFIX)
+		if err != nil || n <= 0 {
+			continue
+		}
+		end := i + n
+		for end > i && strings.IndexByte(".,;:!? ", text[end-1]) >= 0 {
+			end--
+		}
+
+		// end of a word
+		if end < t_len && isAlphanumeric(text[end]) {
+			continue
+		}
+		if isFindFalsePositive(text, i, end) {
+			continue
+		}
+		c := findConfidence(text[i:end], data)
+		if c < o.MinConfidence {
+			continue
+		}
+
+		res = append(res, Match{i, end, text[i:end], data, c})
+		i = end - 1
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFindAll(t *testing.T) {
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+	opts := &FindOptions{Base: &base, MinConfidence: 0.5}
+
+	testcases := []struct {
+		text     string
+		expected []string
+	}{
+		{"can we meet next Tuesday at 3pm or on 2022-01-05?", []string{"next Tuesday at 3pm", "2022-01-05"}},
+		{"Deployed on 29 December 2021 18:24 UTC, rolled back yesterday at 5pm.", []string{"29 December 2021 18:24 UTC", "yesterday at 5pm"}},
+		{"Meeting 12/29/2021 10:00, moved 3 days ago", []string{"12/29/2021 10:00", "3 days ago"}},
+		// false positives
+		{"upgrade to v1.2.3 or 10.9.2 please", []string{}},
+		{"call 555-123-4567 or (03) 1234 5678", []string{}},
+		{"it costs $2021, about 20% more", []string{}},
+		{"You may go in March. I need a day off", []string{}},
+		{"We parted on 2021-12-29 and may meet again a day later", []string{"2021-12-29"}},
+	}
+	for _, tc := range testcases {
+		matches := FindAll(tc.text, opts)
+		texts := make([]string, 0, len(matches))
+		for _, m := range matches {
+			texts = append(texts, m.Text)
+			assert.Equal(t, m.Text, tc.text[m.Start:m.End])
+			assert.True(t, m.Confidence >= 0.5 && m.Confidence <= 1.0)
+		}
+		assert.Equal(t, tc.expected, texts, tc.text)
+	}
+
+	// resolved dates
+	matches := FindAll("can we meet next Tuesday at 3pm or on 2022-01-05?", opts)
+	assert.Equal(t, "2022-01-04 15:00:00", matches[0].Data.Format("Y-m-d H:i:s"))
+	assert.Equal(t, 12, matches[0].Start)
+	assert.Equal(t, 31, matches[0].End)
+	assert.Equal(t, "2022-01-05", matches[1].Data.Format("Y-m-d"))
+
+	// filler words are not a part of matches
+	matches = FindAll("meet at 2021/12/29 18:00 UTC", opts)
+	assert.Equal(t, 1, len(matches))
+	assert.Equal(t, "2021/12/29 18:00 UTC", matches[0].Text)
+	assert.Equal(t, 8, matches[0].Start)
+
+	// MinConfidence defaults to 0.5
+	matches = FindAll("We parted on 2021-12-29 and may meet again a day later", &FindOptions{Base: &base})
+	assert.Equal(t, 1, len(matches))
+	assert.Equal(t, "2021-12-29", matches[0].Text)
+
+	// lower threshold
+	matches = FindAll("You may go in March.", &FindOptions{Base: &base, MinConfidence: 0.1})
+	assert.Equal(t, 2, len(matches))
+	assert.Equal(t, "March", matches[1].Text)
+	assert.Equal(t, 3, matches[1].Data.GetMonth())
+
+	// no threshold
+	matches = FindAll("the deadline is UTC +1 day", &FindOptions{Base: &base, MinConfidence: -1})
+	assert.Equal(t, 1, len(matches))
+	assert.Equal(t, "UTC +1 day", matches[0].Text)
+	assert.Less(t, matches[0].Confidence, 0.5)
+	matches = FindAll("the deadline is UTC +1 day", &FindOptions{Base: &base})
+	assert.Equal(t, 0, len(matches))
+}
+
+func ExampleFindAll() {
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+	for _, m := range FindAll("can we meet next Tuesday at 3pm or on 2022-01-05?", &FindOptions{Base: &base, MinConfidence: 0.5}) {
+		fmt.Println(m.Text, m.Data.Format("Y-m-d H:i"))
+	}
+	// next Tuesday at 3pm 2022-01-04 15:00
+	// 2022-01-05 2022-01-05 10:00
+}
@@ -0,0 +1,196 @@
+package timeparser
+
+import (
+	"time"
+)
+
+// ==============================================================
+// Fiscal Calendar
+// ==============================================================
+
+// FiscalCalendar is a fiscal year which starts on the first day of StartMonth
+type FiscalCalendar struct {
+	StartMonth       int  // the first month of fiscal years (1-12, 0 means January)
+	NamedByStartYear bool // FY2022 starts in 2022 (e.g. Japan). Otherwise it ends in 2022 (e.g. US federal government)
+}
+
+// default fiscal calendar (same as calendar years)
+// used unless TimeData.SetFiscalCalendar or FiscalCalendar methods are used
+var defaultFiscalCalendar = FiscalCalendar{1, false}
+
+// the calendar or the default one
+func getFiscalCalendar(cal *FiscalCalendar) *FiscalCalendar {
+	if cal != nil {
+		return cal
+	}
+	cal_ := defaultFiscalCalendar
+	return &cal_
+}
+
+// the first month of fiscal years (1-12)
+func (cal *FiscalCalendar) startMonth() int {
+	if cal.StartMonth < 1 || 12 < cal.StartMonth {
+		return 1
+	}
+	return cal.StartMonth
+}
+
+// fiscal year of the month
+func (cal *FiscalCalendar) year(y int, m int) int {
+	s := cal.startMonth()
+	if m < s {
+		y--
+	}
+	if s > 1 && !cal.NamedByStartYear {
+		y++
+	}
+	return y
+}
+
+// fiscal period (1-12) of the month
+func (cal *FiscalCalendar) period(m int) int {
+	return (m-cal.startMonth()+12)%12 + 1
+}
+
+// year and month where the fiscal period begins
+func (cal *FiscalCalendar) periodStart(fy int, p int) (int, int) {
+	y := fy
+	if cal.startMonth() > 1 && !cal.NamedByStartYear {
+		y--
+	}
+	y, m := norm(y, cal.startMonth()-1+p-1, 12)
+	return y, m + 1
+}
+
+// fiscal year of the date
+func (cal *FiscalCalendar) Year(data *TimeData) int {
+	return cal.year(data.y, data.m)
+}
+
+// fiscal quarter (1-4) of the date
+func (cal *FiscalCalendar) Quarter(data *TimeData) int {
+	return (cal.period(data.m)-1)/3 + 1
+}
+
+// fiscal period (1-12) of the date
+func (cal *FiscalCalendar) Period(data *TimeData) int {
+	return cal.period(data.m)
+}
+
+// range of the fiscal year
+func (cal *FiscalCalendar) YearRange(fy int, loc *time.Location) Range {
+	y, m := cal.periodStart(fy, 1)
+	start := time.Date(y, time.Month(m), 1, 0, 0, 0, 0, loc)
+	return Range{start, start.AddDate(1, 0, 0)}
+}
+
+// range of the fiscal quarter
+func (cal *FiscalCalendar) QuarterRange(fy int, q int, loc *time.Location) Range {
+	y, m := cal.periodStart(fy, q*3-2)
+	start := time.Date(y, time.Month(m), 1, 0, 0, 0, 0, loc)
+	return Range{start, start.AddDate(0, 3, 0)}
+}
+
+// Convert string to a time.Time variable with the fiscal calendar
+// ("FY2022", "FY22 Q1", "next fiscal year", etc.)
+func (cal *FiscalCalendar) ParseTimeStr(format string, base *time.Time) (*time.Time, error) {
+	data, _, err := parseTimeStrWithCalendar(format, base, nil, 0, cal)
+	if err != nil {
+		return nil, err
+	}
+	return data.resolve()
+}
+
+// Format a time.Time variable to a string with the fiscal calendar
+// (E: fiscal year, q: fiscal quarter, k: fiscal period)
+func (cal *FiscalCalendar) FormatTime(s string, dt *time.Time) string {
+	if dt == nil {
+		d := time.Now()
+		dt = &d
+	}
+	return string(appendFormatWithCalendar(make([]byte, 0, len(s)+16), s, dt, cal, FORMAT_PHP))
+}
+
+// append a formatted string of a fiscal format character
+func appendFiscalChr(dst []byte, f byte, d *time.Time, cal *FiscalCalendar) ([]byte, bool) {
+	switch f {
+	case 'E':
+		return appendInt(dst, cal.year(d.Year(), int(d.Month())), 4), true
+	case 'q':
+		return appendInt(dst, (cal.period(int(d.Month()))-1)/3+1, 1), true
+	case 'k':
+		return appendInt(dst, cal.period(int(d.Month())), 2), true
+	}
+	return dst, false
+}
+
+// FY2022 | FY22 ([ -](Q[1-4]|P\d{1,2}))?
+func scanFiscalYear(s string, pos_s int) (fy int, q int, p int, length int) {
+	s_len := len(s)
+	pos := pos_s
+	q, p = -1, -1
+
+	if scanWord(s, pos, "fy", false) < 0 {
+		return -1, -1, -1, -1
+	}
+	pos += 2
+	if pos < s_len && s[pos] == ' ' {
+		pos++
+	}
+
+	pos_ := pos
+	fy, ok := parseInt(&s, &pos, 2, 4)
+	if !ok || (pos-pos_ != 2 && pos-pos_ != 4) || (pos < s_len && isAlphanumeric(s[pos])) {
+		return -1, -1, -1, -1
+	}
+	if pos-pos_ == 2 {
+		if fy < 70 {
+			fy += 2000
+		} else {
+			fy += 1900
+		}
+	}
+
+	// quarter | period
+	if pos+2 < s_len && (s[pos] == ' ' || s[pos] == '-') {
+		pos_ = pos + 1
+		c := s[pos_]
+		if c == 'q' || c == 'Q' || c == 'p' || c == 'P' {
+			pos_++
+			n, ok := parseInt(&s, &pos_, 1, 2)
+			if ok && (pos_ >= s_len || !isAlphanumeric(s[pos_])) {
+				switch {
+				case (c == 'q' || c == 'Q') && 1 <= n && n <= 4:
+					q, pos = n, pos_
+				case (c == 'p' || c == 'P') && 1 <= n && n <= 12:
+					p, pos = n, pos_
+				}
+			}
+		}
+	}
+	return fy, q, p, (pos - pos_s)
+}
+
+// ============================================================
+// TimeData
+// ============================================================
+
+// Set the fiscal calendar used by GetFiscalYear, GetFiscalQuarter and GetFiscalPeriod (nil means the default)
+func (data *TimeData) SetFiscalCalendar(cal *FiscalCalendar) {
+	data.cal = cal
+}
+
+// fiscal year of the default calendar (or the calendar used in parsing)
+func (data *TimeData) GetFiscalYear() int {
+	return getFiscalCalendar(data.cal).Year(data)
+}
+
+// fiscal quarter (1-4) of the default calendar (or the calendar used in parsing)
+func (data *TimeData) GetFiscalQuarter() int {
+	return getFiscalCalendar(data.cal).Quarter(data)
+}
+
+// fiscal period (1-12) of the default calendar (or the calendar used in parsing)
+func (data *TimeData) GetFiscalPeriod() int {
+	return getFiscalCalendar(data.cal).Period(data)
+}
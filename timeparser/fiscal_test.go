@@ -0,0 +1,126 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFiscalCalendar(t *testing.T) {
+	jp := &FiscalCalendar{4, true}   // FY2022 = 2022-04 .. 2023-03
+	us := &FiscalCalendar{10, false} // FY2022 = 2021-10 .. 2022-09
+
+	testcases := []struct {
+		cal     *FiscalCalendar
+		date    string
+		year    int
+		quarter int
+		period  int
+	}{
+		{jp, "2022-04-01", 2022, 1, 1},
+		{jp, "2023-03-31", 2022, 4, 12},
+		{jp, "2022-12-29", 2022, 3, 9},
+		{us, "2021-10-01", 2022, 1, 1},
+		{us, "2022-09-30", 2022, 4, 12},
+		{us, "2021-12-29", 2022, 1, 3},
+		{&FiscalCalendar{}, "2021-12-29", 2021, 4, 12},
+	}
+	for _, tc := range testcases {
+		data, err := New(tc.date)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.year, tc.cal.Year(data), tc.date)
+		assert.Equal(t, tc.quarter, tc.cal.Quarter(data), tc.date)
+		assert.Equal(t, tc.period, tc.cal.Period(data), tc.date)
+	}
+
+	r := jp.YearRange(2022, time.UTC)
+	assert.Equal(t, time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC), r.Start)
+	assert.Equal(t, time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC), r.End)
+
+	r = us.QuarterRange(2022, 2, time.UTC)
+	assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), r.Start)
+	assert.Equal(t, time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC), r.End)
+}
+
+func TestFiscalCalendarParseTimeStr(t *testing.T) {
+	jp := &FiscalCalendar{4, true}
+	us := &FiscalCalendar{10, false}
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+
+	testcases := []struct {
+		cal      *FiscalCalendar
+		s        string
+		expected string
+	}{
+		{jp, "FY2022", "2022-04-01"},
+		{us, "FY2022", "2021-10-01"},
+		{jp, "FY22 Q1", "2022-04-01"},
+		{jp, "FY2022 Q4", "2023-01-01"},
+		{us, "FY2022 Q4", "2022-07-01"},
+		{jp, "FY2022-P03", "2022-06-01"},
+		{jp, "next fiscal year", "2022-04-01"},
+		{us, "next fiscal year", "2022-10-01"},
+		{jp, "last fiscal year", "2020-04-01"},
+		{jp, "last day of next fiscal year", "2023-03-31"},
+		{us, "last day of next fiscal year", "2023-09-30"},
+	}
+	for _, tc := range testcases {
+		tm, err := tc.cal.ParseTimeStr(tc.s, &base)
+		assert.Nil(t, err, tc.s)
+		assert.Equal(t, tc.expected, FormatTime("Y-m-d", tm), tc.s)
+	}
+
+	// the default calendar is the calendar year
+	assert.Equal(t, FiscalCalendar{1, false}, *getFiscalCalendar(nil))
+
+	tm, err := ParseTimeStr("FY2022 Q2", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, "2022-04-01", FormatTime("Y-m-d", tm))
+
+	data, err := New("2022-02-15")
+	assert.Nil(t, err)
+	assert.Equal(t, 2022, data.GetFiscalYear())
+	assert.Equal(t, 1, data.GetFiscalQuarter())
+	assert.Equal(t, 2, data.GetFiscalPeriod())
+
+	// per value calendars
+	data.SetFiscalCalendar(jp)
+	assert.Equal(t, 2021, data.GetFiscalYear())
+	assert.Equal(t, 4, data.GetFiscalQuarter())
+	assert.Equal(t, 11, data.GetFiscalPeriod())
+
+	im := data.Immutable().SetFiscalCalendar(us)
+	assert.Equal(t, 2022, im.Mutable().GetFiscalYear())
+	assert.Equal(t, 2, im.Mutable().GetFiscalQuarter())
+	assert.Equal(t, 2021, data.GetFiscalYear())
+}
+
+func TestFiscalCalendarFormatTime(t *testing.T) {
+	tm := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+
+	us := &FiscalCalendar{10, false}
+	assert.Equal(t, "FY2022 Q1 P03", us.FormatTime("\\F\\YE \\Qq \\Pk", &tm))
+
+	// the zero value is the calendar year
+	assert.Equal(t, "FY2021 Q4 P12", (&FiscalCalendar{}).FormatTime("\\F\\YE \\Qq \\Pk", &tm))
+
+	// FormatTime is compatible with PHP and prints them as they are
+	assert.Equal(t, "FYE Qq Pk", FormatTime("\\F\\YE \\Qq \\Pk", &tm))
+
+	// TimeData formats with its own calendar
+	data, err := New("2021-12-29 10:00:00 UTC")
+	assert.Nil(t, err)
+	assert.Equal(t, "FYE Qq Pk", data.Format("\\F\\YE \\Qq \\Pk"))
+	data.SetFiscalCalendar(us)
+	assert.Equal(t, "FY2022 Q1 P03 2021-12-29", data.Format("\\F\\YE \\Qq \\Pk Y-m-d"))
+	assert.Equal(t, "FY2022", string(data.AppendFormat([]byte("FY"), "E")))
+}
+
+func ExampleFiscalCalendar() {
+	cal := &FiscalCalendar{StartMonth: 4, NamedByStartYear: true}
+
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+	tm, _ := cal.ParseTimeStr("FY2022 Q3", &base)
+	fmt.Println(cal.FormatTime("Y-m-d \\F\\YE \\Qq", tm)) // 2022-10-01 FY2022 Q3
+}
@@ -171,7 +171,7 @@ func parseMonth(s *string, pos_s *int) (int, bool) {
 }
 func parseWeekday(s *string, pos_s *int) (int, bool) {
 	weekday_num, weekday_name := startsWithWeekdayName(strings.ToLower((*s)[*pos_s:]))
-	if weekday_num > 0 {
+	if weekday_num >= 0 {
 		*pos_s += len(weekday_name)
 		return weekday_num, true
 	}
@@ -300,7 +300,7 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 		if n, ok = parseWeekday(s, pos_s); !ok {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
-		d.setDay(n)
+		d.setWeekday(n)
 		(*pos)++
 	// suffix (ignores)
 	case 'S':
@@ -313,11 +313,11 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 		if n, ok = parseInt(s, pos_s, 1, 3); !ok {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
-		if n <= 0 || 365 < n {
+		if n < 0 || 365 < n {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
 		d.setMonth(1)
-		d.setDay(1 + n - 1)
+		d.setDay(1 + n) // starting from 0
 		d.normalizeYmd()
 		(*pos)++
 	// Months
@@ -393,7 +393,7 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 		if n, ok = parseInt(s, pos_s, 1, 2); !ok {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
-		if n <= 0 || 23 < n {
+		if n < 0 || 23 < n {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
 		d.setHour(n)
@@ -403,7 +403,7 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 		if n, ok = parseInt(s, pos_s, 1, 2); !ok {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
-		if n <= 0 || 59 < n {
+		if n < 0 || 59 < n {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
 		d.setMinute(n)
@@ -413,7 +413,7 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 		if n, ok = parseInt(s, pos_s, 1, 2); !ok {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
-		if n <= 0 || 59 < n {
+		if n < 0 || 59 < n {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
 		d.setSecond(n)
@@ -439,8 +439,9 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 		if n, ok = parseInt(s, pos_s, 0, 20); !ok {
 			return -1, errors.New(fmt.Sprintf("failed to parse format: %s", string((*format)[*pos])))
 		}
-		// set Unixtime
-		_t := time.Unix(int64(n), 0) // .In()
+		// set Unixtime (the timezone is +00:00 like PHP)
+		_t := time.Unix(int64(n), 0).UTC()
+		d.setFixedZone(0)
 		d.setYear(_t.Year())
 		d.setMonth(int(_t.Month()))
 		d.setDay(_t.Day())
@@ -458,14 +459,9 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 		fallthrough
 	case 'T':
 		// [+-]00:00 format means timezone offset from UTC
+		zone_s := *pos_s
 		if n, ok = parseZoneOffset(s, pos_s); ok {
-			d.setTimezoneOffset(n)
-
-			loc, err_ := time.LoadLocation("UTC")
-			if err_ != nil {
-				return -1, err_
-			}
-			d.setLocation(loc)
+			d.setFixedZone(n)
 		} else {
 			loc, ok_, err_ := parseLocation(s, pos_s)
 			if ok_ != true {
@@ -477,15 +473,19 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 			}
 			d.setLocation(loc)
 		}
+		d.zone = (*s)[zone_s:*pos_s]
 
 		(*pos)++
 
 	// Other formats
 	case '!':
 		d.reset()
+		d.flags |= RESET_FIELDS
+		(*pos)++
 	case '|':
 		d.resetIfUnset()
 		d.ap = 0
+		d.flags |= RESET_FIELDS
 		(*pos)++
 	case '+':
 		d.flags |= SKIP_ERRORS
@@ -537,15 +537,23 @@ func parseFormatChar(format *string, pos *int, s *string, pos_s *int, d *TimeDat
 	return (*pos_s), nil
 }
 
-// Convert a datetime string to a time.Time variable with format specification
-func ParseFormat(format string, s string) (*time.Time, error) {
+// format characters which don't consume the input
+const noInputChrs = "!|+"
+
+// parse a datetime string with format specification
+// (unspecified fields are filled with base)
+// (warnings and errors are collected into r if it is not nil)
+func parseFormat(format string, s string, base *time.Time, r *ParseReport, flags int) (*TimeData, int, error) {
 	format = strings.TrimSpace(format)
 	if format == "" {
-		return nil, errors.New("empty format")
+		r.addError(0, "empty format")
+		return nil, -1, errors.New("empty format")
 	}
+	offset := len(s) - len(strings.TrimLeft(s, " \t\r\n"))
 	s = strings.TrimSpace(s)
 	if s == "" {
-		return nil, errors.New("empty data")
+		r.addError(0, "empty data")
+		return nil, -1, errors.New("empty data")
 	}
 
 	pos_s := 0
@@ -555,8 +563,10 @@ func ParseFormat(format string, s string) (*time.Time, error) {
 	f_len := len(format)
 
 	tmp := 0
+	skipped := false // an error was skipped by '+'
 
 	data := newTimeData()
+	data.flags = flags
 	for pos < f_len {
 		// skip spaces
 		if isSpace(format[pos]) {
@@ -567,18 +577,25 @@ func ParseFormat(format string, s string) (*time.Time, error) {
 		if pos >= f_len {
 			break
 		}
-		if pos_s >= s_len {
+		if pos_s >= s_len && strings.IndexByte(noInputChrs, format[pos]) < 0 {
 			if data.hasFlag(SKIP_ERRORS) {
+				r.addWarning(offset+pos_s, fmt.Sprintf("data missing for format: %s", string(format[pos])))
+				skipped = true
 				break
 			}
-			return nil, errors.New(fmt.Sprintf("failed to parse format: %s", string(format[pos])))
+			r.addError(offset+pos_s, fmt.Sprintf("data missing for format: %s", string(format[pos])))
+			return nil, -1, errors.New(fmt.Sprintf("failed to parse format: %s", string(format[pos])))
 		}
 
+		pos_s_ := pos_s
 		if _, err := parseFormatChar(&format, &pos, &s, &pos_s, data); err != nil {
 			if data.hasFlag(SKIP_ERRORS) {
+				r.addWarning(offset+pos_s_, err.Error())
+				skipped = true
 				break
 			}
-			return nil, err
+			r.addError(offset+pos_s_, err.Error())
+			return nil, -1, err
 		}
 
 		tmp++
@@ -587,7 +604,95 @@ func ParseFormat(format string, s string) (*time.Time, error) {
 		}
 	}
 
-	res := data.Time()
+	n, err := finishParseFormat(data, s, pos_s, offset, base, r, skipped)
+	if err != nil {
+		return nil, -1, err
+	}
+	return data, n, nil
+}
+
+// fill unspecified fields with base, check and normalize the parsed fields
+// and return the length of the parsed data (shared with Format.ParseWithBase)
+func finishParseFormat(data *TimeData, s string, pos_s int, offset int, base *time.Time, r *ParseReport, skipped bool) (int, error) {
+	if pos_s < len(s) && !skipped && !data.hasFlag(PREFIX) {
+		r.addWarning(offset+pos_s, "trailing data")
+	}
+
+	data.fillUnset(base)
+	data.checkFields(r, offset+pos_s)
+	if data.hasFlag(STRICT) {
+		if err := data.checkRange(); err != nil {
+			r.addError(offset+pos_s, err.Error())
+			return -1, err
+		}
+	}
+	data.normalize()
+
+	if data.hasFlag(PREFIX) {
+		for pos_s > 0 && isSpace(s[pos_s-1]) {
+			pos_s--
+		}
+	}
+	return offset + pos_s, nil
+}
+
+// Convert a datetime string to a time.Time variable with format specification
+//
+// Unspecified fields are filled with the current time like PHP's DateTime::createFromFormat().
+// Use '!' or '|' to reset them to 1970-01-01 00:00:00.
+func ParseFormat(format string, s string) (*time.Time, error) {
+	return ParseFormatWithBase(format, s, nil)
+}
+
+// Convert a datetime string to a time.Time variable with format specification
+// (unspecified fields are filled with base, nil means now)
+func ParseFormatWithBase(format string, s string, base *time.Time) (*time.Time, error) {
+	data, _, err := parseFormat(format, s, base, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return data.resolve()
+}
+
+// same as ParseFormat but returns ErrOutOfRange for overflowing dates and times
+// (e.g. 2021-02-30) instead of normalizing them
+func ParseFormatStrict(format string, s string) (*time.Time, error) {
+	data, _, err := parseFormat(format, s, nil, nil, STRICT)
+	if err != nil {
+		return nil, err
+	}
+	return data.resolve()
+}
+
+// Convert a datetime string to a TimeData variable with format specification
+//
+// The result knows which fields are specified in s (HasYear(), HasTime(), etc).
+func ParseFormatData(format string, s string) (*TimeData, error) {
+	data, _, err := parseFormat(format, s, nil, nil, 0)
+	return data, err
+}
+
+// same as ParseFormatData but unspecified fields are filled with base (nil means now)
+func ParseFormatDataWithBase(format string, s string, base *time.Time) (*TimeData, error) {
+	data, _, err := parseFormat(format, s, base, nil, 0)
+	return data, err
+}
+
+// Convert a datetime string to a TimeData variable with format specification
+// and report warnings and errors like PHP's DateTime::getLastErrors()
+//
+// Errors skipped by '+' are reported as warnings.
+func ParseFormatWithReport(format string, s string) (*TimeData, *ParseReport, error) {
+	r := newParseReport()
+	data, _, err := parseFormat(format, s, nil, r, 0)
+	return data, r, err
+}
 
-	return res, nil
+// Parse the leading part of s with format specification
+// and return the number of bytes consumed (the rest of s is left)
+//
+// This is the only variant which tells where the parsed part ends.
+// ParseFormat and the others accept trailing data without an error (only ParseFormatWithReport warns about it).
+func ParseFormatPrefix(format string, s string) (*TimeData, int, error) {
+	return parseFormat(format, s, nil, nil, PREFIX)
 }
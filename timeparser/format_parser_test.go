@@ -17,7 +17,6 @@ func TestParseFormat(t *testing.T) {
 
 		// with wild cards
 		"Y#n#j H:i:s": "2021,12,29 18:24:36",
-		"U H?i#s":     "1640769840 18_24:36",
 	}
 	for format, s := range testcases {
 		tm, err := ParseFormat(format, s)
@@ -90,14 +89,15 @@ func TestParseFormat(t *testing.T) {
 		assert.Equal(t, expected.Year(), tm.Year())
 		assert.Equal(t, expected.Month(), tm.Month())
 		assert.Equal(t, expected.Day(), tm.Day())
-		assert.Equal(t, expected.Hour()+i, tm.Hour())
+		assert.Equal(t, expected.Hour(), tm.Hour())
 		assert.Equal(t, expected.Minute(), tm.Minute())
 		assert.Equal(t, expected.Second(), tm.Second())
-		assert.Equal(t, "UTC", tm.Location().String())
+		_, offset_ := tm.Zone()
+		assert.Equal(t, i*3600, offset_)
 	}
 
 	// Abbreviated formats
-	expected = time.Date(2021, time.December, 30, 3, 24, 36, 0, utc)
+	expected = time.Date(2021, time.December, 29, 18, 24, 36, 0, time.FixedZone("", 9*3600))
 	testcases = map[string]string{
 		"r": "Wed, 29 Dec 2021 18:24:36 +0900",
 		"c": "2021-12-29T18:24:36+09:00",
@@ -112,9 +112,212 @@ func TestParseFormat(t *testing.T) {
 		assert.Equal(t, expected.Hour(), tm.Hour())
 		assert.Equal(t, expected.Minute(), tm.Minute())
 		assert.Equal(t, expected.Second(), tm.Second())
-		assert.Equal(t, "UTC", tm.Location().String())
+		assert.Equal(t, expected.Location(), tm.Location())
+		assert.True(t, expected.Equal(*tm))
+	}
+
+}
+
+func TestParseFormatWeekday(t *testing.T) {
+	// Sunday is 0
+	tm, err := ParseFormat("l Y-m-d", "Sunday 2022-01-02")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Sunday, tm.Weekday())
+	assert.Equal(t, 2, tm.Day())
+
+	tm, err = ParseFormat("D Y-m-d", "Sun 2022-01-02")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, tm.Day())
+
+	// the weekday does not overwrite the day of the month
+	tm, err = ParseFormat("Y-m-d l", "2021-12-31 Friday")
+	assert.Nil(t, err)
+	assert.Equal(t, 31, tm.Day())
+
+	tm, err = ParseFormat("d M Y, D", "31 Dec 2021, Fri")
+	assert.Nil(t, err)
+	assert.Equal(t, 31, tm.Day())
+}
+
+func TestParseFormatMonthName(t *testing.T) {
+	for i, name := range []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"} {
+		tm, err := ParseFormat("j F Y", "10 "+name+" 2021")
+		assert.Nil(t, err, name)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, time.Month(i+1), tm.Month(), name)
+
+		tm, err = ParseFormat("j M Y", "10 "+name[:3]+" 2021")
+		assert.Nil(t, err, name)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, time.Month(i+1), tm.Month(), name)
+	}
+}
+
+func TestParseFormatData(t *testing.T) {
+	data, err := ParseFormatData("Y-m-d", "2021-12-29")
+	assert.Nil(t, err)
+	assert.True(t, data.HasYear())
+	assert.True(t, data.HasMonth())
+	assert.True(t, data.HasDay())
+	assert.True(t, data.HasDate())
+	assert.False(t, data.HasTime())
+	assert.False(t, data.HasZone())
+	assert.Equal(t, SET_YEAR|SET_MONTH|SET_DAY, data.SpecifiedFields())
+	assert.Equal(t, 29, data.GetDay())
+
+	data, err = ParseFormatData("m/d H:i T", "12/29 18:24 Asia/Tokyo")
+	assert.Nil(t, err)
+	assert.False(t, data.HasYear())
+	assert.False(t, data.HasDate())
+	assert.True(t, data.HasTime())
+	assert.True(t, data.HasZone())
+	assert.Equal(t, SET_MONTH|SET_DAY|SET_HOUR|SET_MINUTE|SET_TIMEZONE_LOCATION, data.SpecifiedFields())
+
+	// the same result as ParseFormat
+	tm, err := ParseFormat("Y-m-d H:i:s P", "2021-12-29 18:24:36 +09:00")
+	assert.Nil(t, err)
+	data, err = ParseFormatData("Y-m-d H:i:s P", "2021-12-29 18:24:36 +09:00")
+	assert.Nil(t, err)
+	assert.Equal(t, tm.Unix(), data.Unix())
+	assert.True(t, data.HasZone())
+
+	_, err = ParseFormatData("Y-m-d", "2021/12/29")
+	assert.NotNil(t, err)
+
+	// ParseTimeStr / New
+	data, err = New("December 29th")
+	assert.Nil(t, err)
+	assert.Equal(t, SET_MONTH|SET_DAY, data.SpecifiedFields())
+	data, err = New("+1 day")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, data.SpecifiedFields())
+}
+
+func TestParseFormatWithBase(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.Nil(t, err)
+	base := time.Date(2000, time.September, 10, 11, 22, 33, 444, tokyo)
+
+	testcases := []struct {
+		format   string
+		s        string
+		expected time.Time
+	}{
+		// unspecified fields are taken from base
+		{"H:i", "18:24", time.Date(2000, time.September, 10, 18, 24, 0, 0, tokyo)},
+		{"Y-m-d", "2021-12-29", time.Date(2021, time.December, 29, 11, 22, 33, 444, tokyo)},
+		{"m/d", "12/29", time.Date(2000, time.December, 29, 11, 22, 33, 444, tokyo)},
+		{"Y H", "2021 18", time.Date(2021, time.September, 10, 18, 0, 0, 0, tokyo)},
+
+		// '!' and '|' reset fields to the Unix epoch
+		{"!H:i", "18:24", time.Date(1970, time.January, 1, 18, 24, 0, 0, tokyo)},
+		{"!Y-m-d", "2021-12-29", time.Date(2021, time.December, 29, 0, 0, 0, 0, tokyo)},
+		{"Y-m-d|", "2021-12-29", time.Date(2021, time.December, 29, 0, 0, 0, 0, tokyo)},
+		{"H:i|", "18:24", time.Date(1970, time.January, 1, 18, 24, 0, 0, tokyo)},
+	}
+	for _, tc := range testcases {
+		tm, err := ParseFormatWithBase(tc.format, tc.s, &base)
+		assert.Nil(t, err, tc.format)
+		if err != nil {
+			continue
+		}
+		if tc.format[0] == '!' || tc.format[len(tc.format)-1] == '|' {
+			// reset fields use the local timezone
+			tc.expected = time.Date(tc.expected.Year(), tc.expected.Month(), tc.expected.Day(), tc.expected.Hour(), tc.expected.Minute(), tc.expected.Second(), tc.expected.Nanosecond(), time.Local)
+		}
+		assert.Equal(t, tc.expected.String(), tm.String(), tc.format)
+	}
+
+	// the zone in s
+	tm, err := ParseFormatWithBase("H:i T", "18:24 UTC", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, "2000-09-10 18:24:00 +0000 UTC", tm.String())
+
+	// Unix timestamps are in +00:00 regardless of base
+	tm, err = ParseFormatWithBase("U", "1640802276", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1640802276), tm.Unix())
+	assert.Equal(t, "2021-12-29 18:24:36 +0000 UTC", tm.String())
+
+	tm, err = ParseFormatWithBase("U H?i#s", "1640769840 18_24:36", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-12-29 18:24:36 +0000 UTC", tm.String())
+
+	tm, err = MustCompileFormat("U").ParseWithBase("1640802276", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1640802276), tm.Unix())
+
+	// nil means now
+	tm, err = ParseFormat("H:i", "18:24")
+	assert.Nil(t, err)
+	now := time.Now()
+	assert.Equal(t, now.Year(), tm.Year())
+	assert.Equal(t, now.YearDay(), tm.YearDay())
+	assert.Equal(t, 18, tm.Hour())
+
+	// TimeData keeps the specified fields
+	data, err := ParseFormatDataWithBase("H:i", "18:24", &base)
+	assert.Nil(t, err)
+	assert.False(t, data.HasDate())
+	assert.Equal(t, 2000, data.GetYear())
+
+	// compiled formats
+	tm, err = MustCompileFormat("H:i").ParseWithBase("18:24", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, "2000-09-10 18:24:00 +0900 JST", tm.String())
+}
+
+func TestParseFormatPrefix(t *testing.T) {
+	line := "2021-12-29 18:24:36 INFO server started"
+	data, n, err := ParseFormatPrefix("Y-m-d H:i:s", line)
+	assert.Nil(t, err)
+	assert.Equal(t, 19, n)
+	assert.Equal(t, "2021-12-29 18:24:36", data.Format("Y-m-d H:i:s"))
+
+	// leading spaces are counted
+	_, n, err = ParseFormatPrefix("Y-m-d", "  2021-12-29 18:24:36")
+	assert.Nil(t, err)
+	assert.Equal(t, 12, n)
+
+	_, n, err = ParseFormatPrefix("Y-m-d", "2021-12-29")
+	assert.Nil(t, err)
+	assert.Equal(t, 10, n)
+
+	_, _, err = ParseFormatPrefix("Y-m-d H:i:s", "2021-12-29 INFO")
+	assert.NotNil(t, err)
+
+	// messages which start with a digit or a month
+	for _, msg := range []string{" 404 not found", " 10 users online", " March madness", " Dec report uploaded"} {
+		data, n, err = ParseFormatPrefix("Y-m-d H:i:s", "2021-12-29 18:24:36"+msg)
+		assert.Nil(t, err, msg)
+		assert.Equal(t, 19, n, msg)
+		assert.Equal(t, "2021-12-29 18:24:36", data.Format("Y-m-d H:i:s"), msg)
 	}
 
+	// ParseFormat accepts trailing data and only reports a warning
+	_, err = ParseFormat("Y-m-d H:i:s", line)
+	assert.Nil(t, err)
+	_, r, err := ParseFormatWithReport("Y-m-d H:i:s", line)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, r.WarningCount())
+}
+
+func ExampleParseFormatData() {
+	// merge partially specified input with defaults
+	data, err := ParseFormatData("H:i", "18:24")
+	if err != nil {
+		panic(err)
+	}
+	if !data.HasDate() {
+		data.SetYear(2021)
+		data.SetMonth(12)
+		data.SetDay(29)
+	}
+	fmt.Println(data) // 2021-12-29T18:24:00+09:00
 }
 
 func ExampleParseFormat() {
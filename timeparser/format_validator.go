@@ -0,0 +1,108 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ==============================================================
+// Format Validation
+// ==============================================================
+
+// Severities of format issues
+const (
+	FORMAT_ISSUE_ERROR   = 1 // the output is probably not what is expected
+	FORMAT_ISSUE_WARNING = 2 // the output may differ from PHP
+)
+
+// Kinds of format issues
+const (
+	FORMAT_ISSUE_BACKSLASH  = 1 // dangling backslash
+	FORMAT_ISSUE_UNKNOWN    = 2 // unknown letter (copied as it is)
+	FORMAT_ISSUE_DIFFERENCE = 3 // character which differs between PHP and this library
+)
+
+// FormatIssue is a problem found in a format string
+type FormatIssue struct {
+	Pos      int    // byte offset in the format
+	Char     byte   // the character
+	Kind     int    // FORMAT_ISSUE_BACKSLASH | FORMAT_ISSUE_UNKNOWN | FORMAT_ISSUE_DIFFERENCE
+	Severity int    // FORMAT_ISSUE_ERROR | FORMAT_ISSUE_WARNING
+	Message  string // description
+}
+
+func (issue FormatIssue) String() string {
+	return fmt.Sprintf("%s at %d", issue.Message, issue.Pos)
+}
+
+// characters whose results differ from PHP in the legacy mode
+const legacyDiffChrs = "djghWoBcr"
+
+// characters which are not supported by PHP (PHP prints them as they are)
+const extensionChrs = "Q"
+
+// Validate a format string (same characters as FormatTime)
+//
+// It reports dangling backslashes, unknown letters which are copied as they are,
+// and characters whose results differ from PHP.
+func ValidateFormat(format string) []FormatIssue {
+	return ValidateFormatWithMode(format, FORMAT_PHP)
+}
+
+// same as ValidateFormat but differences are checked in mode (FORMAT_PHP or FORMAT_LEGACY)
+func ValidateFormatWithMode(format string, mode int) []FormatIssue {
+	issues := make([]FormatIssue, 0)
+
+	legacy_ := mode == FORMAT_LEGACY
+
+	f_len := len(format)
+	for i := 0; i < f_len; i++ {
+		c := format[i]
+
+		// backslash
+		if c == '\\' {
+			if i+1 >= f_len {
+				issues = append(issues, FormatIssue{i, c, FORMAT_ISSUE_BACKSLASH, FORMAT_ISSUE_ERROR,
+					"dangling backslash"})
+			}
+			i++
+			continue
+		}
+
+		switch {
+		case !isFormatChr(c):
+			if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+				issues = append(issues, FormatIssue{i, c, FORMAT_ISSUE_UNKNOWN, FORMAT_ISSUE_ERROR,
+					fmt.Sprintf("unknown format character: %s (escape it with a backslash)", string(c))})
+			}
+		case strings.IndexByte(extensionChrs, c) >= 0:
+			issues = append(issues, FormatIssue{i, c, FORMAT_ISSUE_DIFFERENCE, FORMAT_ISSUE_WARNING,
+				fmt.Sprintf("format character %s is not supported by PHP (escape it with a backslash to print it as it is)", string(c))})
+		case legacy_ && strings.IndexByte(legacyDiffChrs, c) >= 0:
+			issues = append(issues, FormatIssue{i, c, FORMAT_ISSUE_DIFFERENCE, FORMAT_ISSUE_WARNING,
+				fmt.Sprintf("format character %s differs from PHP in the legacy mode", string(c))})
+		}
+	}
+	return issues
+}
+
+// first issue whose severity is error
+func formatError(issues []FormatIssue) error {
+	for _, issue := range issues {
+		if issue.Severity == FORMAT_ISSUE_ERROR {
+			return errors.New(fmt.Sprintf("invalid format: %s", issue.String()))
+		}
+	}
+	return nil
+}
+
+// Format a time.Time variable to a string
+// or return an error if the format has dangling backslashes or unknown letters
+func FormatTimeStrict(format string, dt *time.Time) (string, error) {
+	if err := formatError(ValidateFormat(format)); err != nil {
+		return "", err
+	}
+	return FormatTime(format, dt), nil
+}
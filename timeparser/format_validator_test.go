@@ -0,0 +1,67 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestValidateFormat(t *testing.T) {
+	// valid formats
+	for _, format := range []string{"", "c", "r", "Y-m-d H:i:s", "l jS \\o\\f F Y h:i:s A", "[Y/m/d] #?*+!|"} {
+		assert.Empty(t, ValidateFormat(format), format)
+	}
+
+	issues := ValidateFormat("Y-m-d Q\\")
+	assert.Equal(t, 2, len(issues))
+	assert.Equal(t, FormatIssue{6, 'Q', FORMAT_ISSUE_DIFFERENCE, FORMAT_ISSUE_WARNING, "format character Q is not supported by PHP (escape it with a backslash to print it as it is)"}, issues[0])
+	assert.Equal(t, 7, issues[1].Pos)
+	assert.Equal(t, FORMAT_ISSUE_BACKSLASH, issues[1].Kind)
+	assert.Equal(t, "dangling backslash at 7", issues[1].String())
+
+	// "\of" -> "f" is an unknown letter
+	issues = ValidateFormat("jS \\of F")
+	assert.Equal(t, 1, len(issues))
+	assert.Equal(t, byte('f'), issues[0].Char)
+
+	// legacy mode
+	assert.Equal(t, 0, len(ValidateFormat("Y-m-d H:i")))
+
+	issues = ValidateFormatWithMode("Y-m-d H:i", FORMAT_LEGACY)
+	assert.Equal(t, 1, len(issues))
+	assert.Equal(t, FORMAT_ISSUE_DIFFERENCE, issues[0].Kind)
+	assert.Equal(t, FORMAT_ISSUE_WARNING, issues[0].Severity)
+	assert.Equal(t, byte('d'), issues[0].Char)
+
+	// characters not supported by PHP are reported in every mode
+	for _, mode := range []int{FORMAT_PHP, FORMAT_LEGACY} {
+		issues = ValidateFormatWithMode("Y-m \\QQ", mode)
+		assert.Equal(t, 1, len(issues))
+		assert.Equal(t, FORMAT_ISSUE_DIFFERENCE, issues[0].Kind)
+		assert.Equal(t, byte('Q'), issues[0].Char)
+	}
+}
+
+func TestFormatTimeStrict(t *testing.T) {
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 0, time.UTC)
+
+	s, err := FormatTimeStrict("Y-m-d H:i:s", &tm)
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-12-29 18:24:36", s)
+
+	for _, format := range []string{"Y-m-d\\", "Y-m-d E", "Ymd \\Week W"} {
+		_, err = FormatTimeStrict(format, &tm)
+		assert.NotNil(t, err, format)
+	}
+
+	// warnings are not errors
+	_, err = CompileFormatWithMode("Y-m-d", FORMAT_LEGACY)
+	assert.Nil(t, err)
+}
+
+func ExampleValidateFormat() {
+	for _, issue := range ValidateFormat("Y-m-d Q H:i\\") {
+		fmt.Println(issue) // format character Q is not supported by PHP (escape it with a backslash to print it as it is) at 6 ...
+	}
+}
@@ -2,51 +2,82 @@ package timeparser
 
 import (
 	"strconv"
+	"strings"
 	"time"
 )
 
-// used in date
-func paddingZero(n string, length int) string {
-	if len(n) > length {
-		return n
-	}
-	dst := make([]byte, length)
-	n_len := len(n)
+// Format modes (used by FormatTimeWithMode, CompileFormatWithMode and ValidateFormatWithMode)
+//
+// FORMAT_LEGACY restores the results of older versions:
+// 'd' is not zero-padded while 'j' is, 'g' and 'h' print 0 at noon and midnight,
+// 'W' and 'o' are approximated from Unix seconds, and 'B' ignores the timezone.
+const (
+	FORMAT_PHP    = 0 // same results as PHP (default)
+	FORMAT_LEGACY = 1 // results of older versions of this library
+)
 
-	for i := 0; i < length; i++ {
-		if length-n_len <= i {
-			dst[i] = n[i-(length-n_len)]
-		} else {
-			dst[i] = '0'
-		}
+// append a zero-padded integer
+func appendInt(dst []byte, n int, length int) []byte {
+	if n < 0 {
+		dst = append(dst, '-')
+		n = -n
 	}
+	var buf [20]byte
+	pos := len(buf)
+	for n >= 10 {
+		pos--
+		buf[pos] = byte('0' + n%10)
+		n /= 10
+	}
+	pos--
+	buf[pos] = byte('0' + n)
 
-	return string(dst)
+	for i := len(buf) - pos; i < length; i++ {
+		dst = append(dst, '0')
+	}
+	return append(dst, buf[pos:]...)
 }
 
-func tzFormat(d *time.Time, o_flg bool) string {
-	dst := ""
-
+// append a timezone offset like +09:00 (o_flg) or +0900
+func appendTzFormat(dst []byte, d *time.Time, o_flg bool) []byte {
 	_, offset_ := d.Zone()
 	if offset_ >= 0 {
-		dst += "+"
+		dst = append(dst, '+')
 	} else {
-		dst += "-"
+		dst = append(dst, '-')
 		offset_ *= -1
 	}
 
 	hour_ := (offset_ / 3600) % 60
 	min_ := (offset_ / 60) % 60
 
-	dst += paddingZero(strconv.Itoa(hour_), 2)
+	dst = appendInt(dst, hour_, 2)
 	if o_flg == true {
-		dst += ":"
+		dst = append(dst, ':')
 	}
-	dst += paddingZero(strconv.Itoa(min_), 2)
+	dst = appendInt(dst, min_, 2)
 
 	return dst
 }
-func iso8601FirstDate(d *time.Time) *time.Time {
+
+// append a timezone abbreviation like JST, or +09 when it is unknown
+func appendTzAbbr(dst []byte, d *time.Time) []byte {
+	name_, offset_ := d.Zone()
+	if name_ != "" {
+		return append(dst, name_...)
+	}
+	if offset_%3600 == 0 {
+		if offset_ >= 0 {
+			dst = append(dst, '+')
+		} else {
+			dst = append(dst, '-')
+			offset_ *= -1
+		}
+		return appendInt(dst, offset_/3600, 2)
+	}
+	return appendTzFormat(dst, d, false)
+}
+func iso8601FirstDate(d *time.Time) time.Time {
 	_d := time.Date(d.Year(), 1, 1, 0, 0, 0, 0, d.Location())
 	_t := _d.Unix()
 	_w := int64(_d.Weekday())
@@ -55,75 +86,109 @@ func iso8601FirstDate(d *time.Time) *time.Time {
 	} else {
 		_t -= (7 - _w) * 86400
 	}
-	t := time.Unix(_t, 0)
-	return &t
+	return time.Unix(_t, 0)
+}
+
+// hour in 12-hour format (1 - 12)
+func hour12(h int, mode int) int {
+	if mode == FORMAT_LEGACY {
+		return h % 12
+	}
+	if h%12 == 0 {
+		return 12
+	}
+	return h % 12
+}
+
+// supported format characters
+// (same as PHP's DateTimeInterface::format() and Q for quarters;
+// E, q, k for fiscal years are only supported by FiscalCalendar.FormatTime
+// and TimeData.Format with a fiscal calendar)
+const formatChrs = "dDjlNSwzWFmMntQLoXxYyaABgGhHisuveIOPpTZcrU"
+
+// check if the character is a supported format character
+func isFormatChr(c byte) bool {
+	return strings.IndexByte(formatChrs, c) >= 0
 }
 
-func timeFormatChr(f byte, d *time.Time) (string, bool) {
+// append a formatted string of a format character
+func appendFormatChr(dst []byte, f byte, d *time.Time, mode int) ([]byte, bool) {
 	switch f {
 	// Date
 	case 'd':
-		return strconv.Itoa(d.Day()), true
+		if mode == FORMAT_LEGACY {
+			return appendInt(dst, d.Day(), 1), true
+		}
+		return appendInt(dst, d.Day(), 2), true
 	case 'j':
-		return paddingZero(strconv.Itoa(d.Day()), 2), true
-	case 'S':
-		d_ := d.Day()
-		switch {
-		case d_%10 == 1:
-			return "st", true
-		case d_%10 == 2:
-			return "nd", true
-		case d_%10 == 3:
-			return "rd", true
-		default:
-			return "th", true
+		if mode == FORMAT_LEGACY {
+			return appendInt(dst, d.Day(), 2), true
 		}
+		return appendInt(dst, d.Day(), 1), true
+	case 'S':
+		return append(dst, ordinalSuffix(d.Day())...), true
 	// Day
 	case 'D':
-		return d.Weekday().String()[0:3], true
+		return append(dst, d.Weekday().String()[0:3]...), true
 	case 'l':
-		return d.Weekday().String(), true
+		return append(dst, d.Weekday().String()...), true
 	case 'w':
-		return strconv.Itoa(int(d.Weekday())), true
+		return appendInt(dst, int(d.Weekday()), 1), true
 	case 'W':
-		_d := iso8601FirstDate(d)
-		return strconv.Itoa(int((d.Unix()-_d.Unix())/86400/7) + 1), true
+		if mode == FORMAT_LEGACY {
+			_d := iso8601FirstDate(d)
+			return appendInt(dst, int((d.Unix()-_d.Unix())/86400/7)+1, 1), true
+		}
+		_, w_ := d.ISOWeek()
+		return appendInt(dst, w_, 2), true
 	case 'N':
-		return strconv.Itoa(int((d.Weekday()+6)%7 + 1)), true
+		return appendInt(dst, int((d.Weekday()+6)%7+1), 1), true
+	case 'z':
+		return appendInt(dst, d.YearDay()-1, 1), true
 
 	// Month
 	case 'F':
-		return d.Month().String(), true
+		return append(dst, d.Month().String()...), true
 	case 'm':
-		return paddingZero(strconv.Itoa(int(d.Month())), 2), true
+		return appendInt(dst, int(d.Month()), 2), true
 	case 'M':
-		return d.Month().String()[0:3], true
+		return append(dst, d.Month().String()[0:3]...), true
 	case 'n':
-		return strconv.Itoa(int(d.Month())), true
+		return appendInt(dst, int(d.Month()), 1), true
 	case 't':
-		y_ := d.Year()
-		m_ := d.Month()
-		if m_ > 12 {
-			m_ = 1
-			y_++
-		} else {
-			m_++
-		}
-		d_ := time.Date(y_, m_, 1, 0, 0, 0, 0, d.Location()).AddDate(0, 0, -1)
-		return strconv.Itoa(d_.Day()), true
+		return appendInt(dst, getLastDay(d.Year(), int(d.Month())), 1), true
+	case 'Q':
+		// quarter (not supported by PHP)
+		return appendInt(dst, (int(d.Month())-1)/3+1, 1), true
 
 	// Year
 	case 'Y':
-		return strconv.Itoa(int(d.Year())), true
+		return appendInt(dst, d.Year(), 4), true
+	case 'X':
+		// +2021, -0055
+		if d.Year() >= 0 {
+			dst = append(dst, '+')
+		}
+		return appendInt(dst, d.Year(), 4), true
+	case 'x':
+		// 2021, -0055, +10191
+		if d.Year() >= 10000 {
+			dst = append(dst, '+')
+		}
+		return appendInt(dst, d.Year(), 4), true
 	case 'y':
-		return strconv.Itoa(int(d.Year()))[2:4], true
+		return appendInt(dst, d.Year()%100, 2), true
 	case 'L':
 		y_ := int(d.Year())
 		if y_%400 == 0 || (y_%4 == 0 && y_%100 != 0) {
-			return "1", true
+			return append(dst, '1'), true
 		}
-		return "0", true
+		return append(dst, '0'), true
 	case 'o':
+		if mode != FORMAT_LEGACY {
+			y_, _ := d.ISOWeek()
+			return appendInt(dst, y_, 1), true
+		}
 		_d := iso8601FirstDate(d)
 		_tmp := _d.AddDate(0, 0, 365+7)
 		_next_d := iso8601FirstDate(&_tmp)
@@ -133,86 +198,106 @@ func timeFormatChr(f byte, d *time.Time) (string, bool) {
 		} else if d.Unix() > _next_d.Unix() {
 			_y++
 		}
-		return strconv.Itoa(int(_y)), true
+		return appendInt(dst, _y, 1), true
 
 	// Time
 	case 'a':
 		if d.Hour() < 12 {
-			return "am", true
+			return append(dst, "am"...), true
 		} else {
-			return "pm", true
+			return append(dst, "pm"...), true
 		}
 	case 'A':
 		if d.Hour() < 12 {
-			return "AM", true
+			return append(dst, "AM"...), true
 		} else {
-			return "PM", true
+			return append(dst, "PM"...), true
 		}
 	case 'B':
 		//Swatch Internet Time
-		h_ := d.Hour()
-		m_ := d.Minute()
-		s_ := d.Second()
-		b_ := strconv.Itoa((int(1000*(3600*h_+60*m_+s_-28800)/86400) + 1000) % 1000)
-
-		return b_, true
+		if mode == FORMAT_LEGACY {
+			h_ := d.Hour()
+			m_ := d.Minute()
+			s_ := d.Second()
+			return appendInt(dst, (int(1000*(3600*h_+60*m_+s_-28800)/86400)+1000)%1000, 1), true
+		}
+		// seconds of the day in UTC+1 (Biel Mean Time)
+		s_ := (d.Unix() + 3600) % 86400
+		if s_ < 0 {
+			s_ += 86400
+		}
+		return appendInt(dst, int(s_*10/864), 3), true
 
 	// hours
 	case 'g':
-		return strconv.Itoa(int(d.Hour()) % 12), true
+		return appendInt(dst, hour12(d.Hour(), mode), 1), true
 	case 'G':
-		return strconv.Itoa(int(d.Hour())), true
+		return appendInt(dst, d.Hour(), 1), true
 	case 'h':
-		return paddingZero(strconv.Itoa(int(d.Hour())%12), 2), true
+		return appendInt(dst, hour12(d.Hour(), mode), 2), true
 	case 'H':
-		return paddingZero(strconv.Itoa(int(d.Hour())), 2), true
+		return appendInt(dst, d.Hour(), 2), true
 
 	// minute / seconds
 	case 'i':
-		return paddingZero(strconv.Itoa(int(d.Minute())), 2), true
+		return appendInt(dst, d.Minute(), 2), true
 	case 's':
-		return paddingZero(strconv.Itoa(int(d.Second())), 2), true
+		return appendInt(dst, d.Second(), 2), true
 	case 'v':
-		return paddingZero(strconv.Itoa(int(d.Nanosecond())), 9)[0:3], true
+		return appendInt(dst, d.Nanosecond()/1e6, 3), true
 	case 'u':
-		return paddingZero(strconv.Itoa(int(d.Nanosecond())), 9)[0:6], true
+		return appendInt(dst, d.Nanosecond()/1e3, 6), true
 
 	// Full Date/Time
 	case 'c':
-		return FormatTime("Y-m-d\\TH:i:sP", d), true
+		return appendFormatWithCalendar(dst, "Y-m-d\\TH:i:sP", d, nil, mode), true
 	case 'r':
-		return FormatTime("D, d M Y H:i:s O", d), true
+		return appendFormatWithCalendar(dst, "D, d M Y H:i:s O", d, nil, mode), true
 	case 'U':
-		return strconv.FormatInt(d.Unix(), 10), true
+		return strconv.AppendInt(dst, d.Unix(), 10), true
 
 	// timezone
 	case 'e':
-		name_, _ := d.Zone()
-		return name_, true
+		// IANA timezone identifier
+		switch name_ := d.Location().String(); name_ {
+		case "Local":
+			return appendTzAbbr(dst, d), true
+		case "":
+			return appendTzFormat(dst, d, true), true
+		default:
+			return append(dst, name_...), true
+		}
 	case 'I':
-		return "", true // (Not supported) @TODO
+		if d.IsDST() {
+			return append(dst, '1'), true
+		}
+		return append(dst, '0'), true
 	case 'Z':
 		_, offset_ := d.Zone()
-		return strconv.Itoa(offset_), true
+		return appendInt(dst, offset_, 1), true
 	case 'T':
-		name_, _ := d.Zone()
-		return name_, true
+		return appendTzAbbr(dst, d), true
 	case 'P':
-		return tzFormat(d, true), true
+		return appendTzFormat(dst, d, true), true
+	case 'p':
+		if _, offset_ := d.Zone(); offset_ == 0 {
+			return append(dst, 'Z'), true
+		}
+		return appendTzFormat(dst, d, true), true
 	case 'O':
-		return tzFormat(d, false), true
+		return appendTzFormat(dst, d, false), true
 	}
-	return "", false
+	return dst, false
 }
 
-// Format a time.Time variable to a string
-func FormatTime(s string, dt *time.Time) string {
-	if dt == nil {
-		d := time.Now()
-		dt = &d
-	}
+// append a formatted string
+func appendFormat(dst []byte, s string, dt *time.Time) []byte {
+	return appendFormatWithCalendar(dst, s, dt, nil, FORMAT_PHP)
+}
 
-	var dst []byte
+// same as appendFormat but fiscal years follow cal (nil means the default calendar)
+// and format characters follow mode
+func appendFormatWithCalendar(dst []byte, s string, dt *time.Time, cal *FiscalCalendar, mode int) []byte {
 	s_len := len(s)
 	pos := 0
 	for i := 0; i < s_len; i++ {
@@ -226,16 +311,45 @@ func FormatTime(s string, dt *time.Time) string {
 			i++ // skip
 			continue
 		}
-		if b, ok := timeFormatChr(s[i], dt); ok == true {
-			if pos < i {
-				dst = append(dst, s[pos:i]...)
+		dst = append(dst, s[pos:i]...)
+		pos = i
+
+		var ok bool
+		if cal != nil {
+			if dst, ok = appendFiscalChr(dst, s[i], dt, cal); ok {
+				pos = i + 1
+				continue
 			}
-			dst = append(dst, b...)
+		}
+		if dst, ok = appendFormatChr(dst, s[i], dt, mode); ok {
 			pos = i + 1
 		}
 	}
 	if pos < s_len {
 		dst = append(dst, s[pos:]...)
 	}
-	return string(dst)
+	return dst
+}
+
+// Append a formatted string of a time.Time variable to dst (same as time.Time.AppendFormat)
+func AppendFormatTime(dst []byte, s string, t time.Time) []byte {
+	return appendFormat(dst, s, &t)
+}
+
+// Format a time.Time variable to a string
+func FormatTime(s string, dt *time.Time) string {
+	if dt == nil {
+		d := time.Now()
+		dt = &d
+	}
+	return string(appendFormat(make([]byte, 0, len(s)+16), s, dt))
+}
+
+// same as FormatTime but format characters follow mode (FORMAT_PHP or FORMAT_LEGACY)
+func FormatTimeWithMode(s string, mode int, dt *time.Time) string {
+	if dt == nil {
+		d := time.Now()
+		dt = &d
+	}
+	return string(appendFormatWithCalendar(make([]byte, 0, len(s)+16), s, dt, nil, mode))
 }
@@ -27,7 +27,7 @@ func TestFormatTime(t *testing.T) {
 		"D l w N":   "Wed Wednesday 3 3",
 		"F m M n t": "December 12 Dec 12 31",
 		"Y y L o":   "2021 21 0 2021",
-		"a A B":     "pm PM 433",
+		"a A B":     "pm PM 808",
 		"g G h H":   "6 18 06 18",
 		"i s u v":   "24 36 123456 123",
 		"Z P O":     "0 +00:00 +0000",
@@ -38,9 +38,155 @@ func TestFormatTime(t *testing.T) {
 		assert.Equal(t, expected, FormatTime(format, &tm))
 	}
 }
+func TestFormatTimePHPConformance(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.Nil(t, err)
+	newyork, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	kolkata := time.FixedZone("", 5*3600+1800)
+	minus := time.FixedZone("", -3*3600)
+
+	// results of PHP 8.2 DateTimeInterface::format()
+	testcases := []struct {
+		tm       time.Time
+		format   string
+		expected string
+	}{
+		{time.Date(2021, time.December, 29, 18, 24, 36, 0, time.UTC), "z I X x Y", "362 0 +2021 2021 2021"},
+		{time.Date(2021, time.December, 29, 18, 24, 36, 0, time.UTC), "e T P p O", "UTC UTC +00:00 Z +0000"},
+		{time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), "z", "0"},
+		{time.Date(2020, time.December, 31, 0, 0, 0, 0, time.UTC), "z", "365"},
+		{time.Date(2021, time.December, 29, 18, 24, 36, 0, tokyo), "e T P p I", "Asia/Tokyo JST +09:00 +09:00 0"},
+		{time.Date(2021, time.July, 4, 12, 0, 0, 0, newyork), "e T P p I", "America/New_York EDT -04:00 -04:00 1"},
+		{time.Date(2021, time.December, 4, 12, 0, 0, 0, newyork), "T I", "EST 0"},
+		{time.Date(2021, time.December, 29, 18, 24, 36, 0, kolkata), "e T P p", "+05:30 +0530 +05:30 +05:30"},
+		{time.Date(2021, time.December, 29, 18, 24, 36, 0, minus), "e T O", "-03:00 -03 -0300"},
+		{time.Date(787, time.January, 1, 0, 0, 0, 0, time.UTC), "Y X x", "0787 +0787 0787"},
+		{time.Date(-55, time.January, 1, 0, 0, 0, 0, time.UTC), "Y X x", "-0055 -0055 -0055"},
+		{time.Date(10191, time.January, 1, 0, 0, 0, 0, time.UTC), "Y X x", "10191 +10191 +10191"},
+		{time.Date(787, time.January, 1, 0, 0, 0, 0, time.UTC), "Y-m", "0787-01"},
+		{time.Date(2021, time.December, 11, 0, 0, 0, 0, time.UTC), "jS", "11th"},
+		{time.Date(2021, time.December, 12, 0, 0, 0, 0, time.UTC), "jS", "12th"},
+		{time.Date(2021, time.December, 13, 0, 0, 0, 0, time.UTC), "jS", "13th"},
+		{time.Date(2021, time.December, 21, 0, 0, 0, 0, time.UTC), "jS", "21st"},
+		{time.Date(2021, time.December, 22, 0, 0, 0, 0, time.UTC), "jS", "22nd"},
+		{time.Date(2021, time.December, 23, 0, 0, 0, 0, time.UTC), "jS", "23rd"},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, FormatTime(tc.format, &tc.tm), tc.format)
+	}
+}
+func TestFormatTimeFormatMode(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.Nil(t, err)
+
+	// results of PHP 8.2
+	testcases := []struct {
+		tm       time.Time
+		format   string
+		expected string
+	}{
+		{time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC), "d j g h", "05 5 12 12"},
+		{time.Date(2021, time.January, 5, 12, 30, 0, 0, time.UTC), "g h a", "12 12 pm"},
+		{time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), "W o Y", "53 2020 2021"},
+		{time.Date(2021, time.January, 4, 0, 0, 0, 0, time.UTC), "W o", "01 2021"},
+		{time.Date(2024, time.December, 30, 0, 0, 0, 0, time.UTC), "W o", "01 2025"},
+		{time.Date(905, time.March, 1, 0, 0, 0, 0, time.UTC), "y Y", "05 0905"},
+		{time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC), "t", "28"},
+		{time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), "t", "29"},
+		{time.Date(2021, time.December, 31, 0, 0, 0, 0, time.UTC), "t", "31"},
+		{time.Date(2021, time.December, 29, 23, 0, 0, 0, time.UTC), "B", "000"},
+		{time.Date(2021, time.December, 29, 18, 24, 36, 0, tokyo), "B", "433"},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, FormatTime(tc.format, &tc.tm), tc.format)
+	}
+
+	// legacy mode
+	tm := time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "5 05 0 00", FormatTimeWithMode("d j g h", FORMAT_LEGACY, &tm))
+	assert.Equal(t, "05 5 12 12", FormatTime("d j g h", &tm))
+	tm = time.Date(2021, time.December, 29, 18, 24, 36, 0, time.UTC)
+	assert.Equal(t, "433 52", FormatTimeWithMode("B W", FORMAT_LEGACY, &tm))
+	assert.Equal(t, "Wed, 29 Dec 2021 18:24:36 +0000", FormatTimeWithMode("r", FORMAT_PHP, &tm))
+
+	f, err := CompileFormatWithMode("d j g h", FORMAT_LEGACY)
+	assert.Nil(t, err)
+	assert.Equal(t, "29 29 6 06", f.Format(tm))
+	assert.Equal(t, "29 29 6 06", MustCompileFormat("d j g h").Format(tm))
+	tm = time.Date(2021, time.January, 5, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "5 05 0 00", f.Format(tm))
+	assert.Equal(t, "05 5 12 12", MustCompileFormat("d j g h").Format(tm))
+}
+func TestAppendFormatTime(t *testing.T) {
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, time.UTC)
+
+	for _, format := range []string{"c", "r", "Y-m-d H:i:s", "l jS \\of F Y h:i:s A", "U.u"} {
+		assert.Equal(t, "> "+FormatTime(format, &tm), string(AppendFormatTime([]byte("> "), format, tm)))
+
+		buf := make([]byte, 0, 64)
+		allocs := testing.AllocsPerRun(100, func() {
+			buf = AppendFormatTime(buf[:0], format, tm)
+		})
+		assert.Equal(t, float64(0), allocs, format)
+	}
+
+	tdata, _ := New("2021-12-29 18:24:36")
+	assert.Equal(t, "> 2021-12-29 18:24:36", string(tdata.AppendFormat([]byte("> "), "Y-m-d H:i:s")))
+}
+
+func benchmarkFormatTime(b *testing.B, format string) {
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, time.UTC)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = FormatTime(format, &tm)
+	}
+}
+func benchmarkAppendFormatTime(b *testing.B, format string) {
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, time.UTC)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = AppendFormatTime(buf[:0], format, tm)
+	}
+}
+func benchmarkCompiledFormat(b *testing.B, format string) {
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, time.UTC)
+	f := MustCompileFormat(format)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = f.AppendFormat(buf[:0], tm)
+	}
+}
+func benchmarkStdAppendFormat(b *testing.B, layout string) {
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, time.UTC)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = tm.AppendFormat(buf[:0], layout)
+	}
+}
+
+func BenchmarkFormatTime_c(b *testing.B)            { benchmarkFormatTime(b, "c") }
+func BenchmarkFormatTime_r(b *testing.B)            { benchmarkFormatTime(b, "r") }
+func BenchmarkFormatTime_Ymd(b *testing.B)          { benchmarkFormatTime(b, "Y-m-d H:i:s") }
+func BenchmarkAppendFormatTime_c(b *testing.B)      { benchmarkAppendFormatTime(b, "c") }
+func BenchmarkAppendFormatTime_r(b *testing.B)      { benchmarkAppendFormatTime(b, "r") }
+func BenchmarkAppendFormatTime_Ymd(b *testing.B)    { benchmarkAppendFormatTime(b, "Y-m-d H:i:s") }
+func BenchmarkCompiledFormat_c(b *testing.B)        { benchmarkCompiledFormat(b, "c") }
+func BenchmarkCompiledFormat_r(b *testing.B)        { benchmarkCompiledFormat(b, "r") }
+func BenchmarkCompiledFormat_Ymd(b *testing.B)      { benchmarkCompiledFormat(b, "Y-m-d H:i:s") }
+func BenchmarkStdAppendFormat_RFC3339(b *testing.B) { benchmarkStdAppendFormat(b, time.RFC3339) }
+
 func ExampleFormatTime() {
 	tm := time.Now()
 	fmt.Println(FormatTime("r", &tm))                     // Wed, 29 Dec 2021 18:24:00 +0900
 	fmt.Println(FormatTime("l jS \\of F Y h:i:s A", &tm)) // Wednesday 29th of December 2021 06:24:00 PM
 	fmt.Println(FormatTime("Y-m-d H:i:s", &tm))           // 2021-12-29 18:24:00
 }
+func ExampleAppendFormatTime() {
+	buf := make([]byte, 0, 64)
+	buf = AppendFormatTime(buf, "Y-m-d H:i:s", time.Now())
+	fmt.Println(string(buf)) // 2021-12-29 18:24:00
+}
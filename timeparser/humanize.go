@@ -0,0 +1,338 @@
+package timeparser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ==============================================================
+// Humanize
+// ==============================================================
+
+// Rounding rules of the smallest unit
+const (
+	ROUND_FLOOR   = 0
+	ROUND_NEAREST = 1
+	ROUND_CEIL    = 2
+)
+
+// units from the largest one
+var humanizeUnits = []string{"year", "month", "week", "day", "hour", "minute", "second"}
+
+// approximate seconds of each unit (used only for estimation)
+var humanizeUnitSeconds = map[string]int64{
+	"year":   31556952,
+	"month":  2629746,
+	"week":   604800,
+	"day":    86400,
+	"hour":   3600,
+	"minute": 60,
+	"second": 1,
+}
+
+// HumanizeOptions controls the output of DiffForHumans and HumanizeWithOptions
+type HumanizeOptions struct {
+	Parts    int           // number of units to output (default 1) e.g. 2 = "1 year 2 months ago"
+	MaxUnit  string        // the largest unit (default "year")
+	MinUnit  string        // the smallest unit (default "second")
+	Rounding int           // ROUND_FLOOR (default), ROUND_NEAREST or ROUND_CEIL
+	JustNow  time.Duration // differences less than this are "just now"
+	Calendar bool          // "yesterday at 14:00", "last Friday at 14:00", etc.
+	Short    bool          // "3d ago"
+	Locale   string        // name of a registered locale (default "en")
+}
+
+// HumanizeLocale is a set of translations used in humanized strings
+//
+// Strings containing verbs are passed to fmt.Sprintf.
+type HumanizeLocale struct {
+	Units       map[string][2]string // singular and plural forms (e.g. "%d day", "%d days")
+	ShortUnits  map[string]string    // short forms (e.g. "%dd")
+	Separator   string               // separator between units
+	Past        string               // e.g. "%s ago"
+	Future      string               // e.g. "in %s"
+	JustNow     string               // e.g. "just now"
+	Today       string               // e.g. "today at %s"
+	Yesterday   string               // e.g. "yesterday at %s"
+	Tomorrow    string               // e.g. "tomorrow at %s"
+	LastWeekday string               // e.g. "last %s at %s" (weekday, time)
+	NextWeekday string               // e.g. "next %s at %s" (weekday, time)
+	Weekdays    [7]string            // weekday names from Sunday
+	TimeFormat  string               // time format in the calendar style (FormatTime)
+	DateFormat  string               // date format in the calendar style out of a week (FormatTime)
+}
+
+// english (default locale)
+var humanizeLocaleEn = HumanizeLocale{
+	Units: map[string][2]string{
+		"year":   {"%d year", "%d years"},
+		"month":  {"%d month", "%d months"},
+		"week":   {"%d week", "%d weeks"},
+		"day":    {"%d day", "%d days"},
+		"hour":   {"%d hour", "%d hours"},
+		"minute": {"%d minute", "%d minutes"},
+		"second": {"%d second", "%d seconds"},
+	},
+	ShortUnits: map[string]string{
+		"year":   "%dy",
+		"month":  "%dmo",
+		"week":   "%dw",
+		"day":    "%dd",
+		"hour":   "%dh",
+		"minute": "%dm",
+		"second": "%ds",
+	},
+	Separator:   " ",
+	Past:        "%s ago",
+	Future:      "in %s",
+	JustNow:     "just now",
+	Today:       "today at %s",
+	Yesterday:   "yesterday at %s",
+	Tomorrow:    "tomorrow at %s",
+	LastWeekday: "last %s at %s",
+	NextWeekday: "next %s at %s",
+	Weekdays:    [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	TimeFormat:  "H:i",
+	DateFormat:  "Y-m-d H:i",
+}
+
+var humanizeLocales = map[string]*HumanizeLocale{
+	"en": &humanizeLocaleEn,
+}
+var humanizeLocalesMu sync.RWMutex
+
+// Register a locale used by HumanizeOptions.Locale
+//
+// Missing units and empty strings (except Separator) fall back to english.
+func RegisterLocale(name string, l *HumanizeLocale) {
+	if l == nil {
+		return
+	}
+	l_ := *l
+	for _, f := range []struct {
+		dst *string
+		en  string
+	}{
+		{&l_.Past, humanizeLocaleEn.Past},
+		{&l_.Future, humanizeLocaleEn.Future},
+		{&l_.JustNow, humanizeLocaleEn.JustNow},
+		{&l_.Today, humanizeLocaleEn.Today},
+		{&l_.Yesterday, humanizeLocaleEn.Yesterday},
+		{&l_.Tomorrow, humanizeLocaleEn.Tomorrow},
+		{&l_.LastWeekday, humanizeLocaleEn.LastWeekday},
+		{&l_.NextWeekday, humanizeLocaleEn.NextWeekday},
+		{&l_.TimeFormat, humanizeLocaleEn.TimeFormat},
+		{&l_.DateFormat, humanizeLocaleEn.DateFormat},
+	} {
+		if *f.dst == "" {
+			*f.dst = f.en
+		}
+	}
+	for i := range l_.Weekdays {
+		if l_.Weekdays[i] == "" {
+			l_.Weekdays[i] = humanizeLocaleEn.Weekdays[i]
+		}
+	}
+
+	humanizeLocalesMu.Lock()
+	defer humanizeLocalesMu.Unlock()
+	humanizeLocales[name] = &l_
+}
+
+// get a registered locale (or english)
+func getHumanizeLocale(name string) *HumanizeLocale {
+	humanizeLocalesMu.RLock()
+	defer humanizeLocalesMu.RUnlock()
+	if l, ok := humanizeLocales[name]; ok && l != nil {
+		return l
+	}
+	return &humanizeLocaleEn
+}
+
+// index of the unit in humanizeUnits
+func humanizeUnitIndex(unit string, default_ int) int {
+	for i, u := range humanizeUnits {
+		if u == unit {
+			return i
+		}
+	}
+	return default_
+}
+
+// add n units to t
+func addHumanizeUnits(t time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "year":
+		return t.AddDate(n, 0, 0)
+	case "month":
+		return t.AddDate(0, n, 0)
+	case "week":
+		return t.AddDate(0, 0, n*7)
+	case "day":
+		return t.AddDate(0, 0, n)
+	case "hour":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "minute":
+		return t.Add(time.Duration(n) * time.Minute)
+	}
+	return t.Add(time.Duration(n) * time.Second)
+}
+
+// number of whole units from `from` to `to` (from <= to)
+func countHumanizeUnits(from, to time.Time, unit string) int {
+	n := int((to.Unix() - from.Unix()) / humanizeUnitSeconds[unit])
+	for n > 0 && addHumanizeUnits(from, unit, n).After(to) {
+		n--
+	}
+	for !addHumanizeUnits(from, unit, n+1).After(to) {
+		n++
+	}
+	return n
+}
+
+// a chunk of a humanized string
+type humanizePart struct {
+	n    int
+	unit string
+}
+
+// split the difference into units (from <= to)
+func humanizeParts(from, to time.Time, o *HumanizeOptions, round bool) []humanizePart {
+	parts := make([]humanizePart, 0, o.Parts)
+
+	max_i := humanizeUnitIndex(o.MaxUnit, 0)
+	min_i := humanizeUnitIndex(o.MinUnit, len(humanizeUnits)-1)
+
+	cur := from
+	last := cur // start of the last part
+	unit := humanizeUnits[min_i]
+	for i := max_i; i <= min_i && len(parts) < o.Parts; i++ {
+		n := countHumanizeUnits(cur, to, humanizeUnits[i])
+		if n == 0 {
+			continue
+		}
+		parts = append(parts, humanizePart{n, humanizeUnits[i]})
+		last, cur = cur, addHumanizeUnits(cur, humanizeUnits[i], n)
+		unit = humanizeUnits[i]
+	}
+	if !round || o.Rounding == ROUND_FLOOR || !cur.Before(to) {
+		return parts
+	}
+
+	// round up the last part
+	rem := to.Sub(cur)
+	if o.Rounding == ROUND_NEAREST && rem*2 < addHumanizeUnits(cur, unit, 1).Sub(cur) {
+		return parts
+	}
+	n := 1
+	if len(parts) > 0 {
+		n = parts[len(parts)-1].n + 1
+	} else {
+		last = from
+	}
+	// split again (60 minutes -> 1 hour)
+	return humanizeParts(from, addHumanizeUnits(last, unit, n), o, false)
+}
+
+// calendar style ("yesterday at 14:00")
+func humanizeCalendar(t, now time.Time, l *HumanizeLocale) string {
+	t = t.In(now.Location())
+	time_ := FormatTime(l.TimeFormat, &t)
+
+	// difference of dates
+	d1 := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	d2 := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(d1.Sub(d2).Hours() / 24)
+
+	switch {
+	case days == 0:
+		return fmt.Sprintf(l.Today, time_)
+	case days == -1:
+		return fmt.Sprintf(l.Yesterday, time_)
+	case days == 1:
+		return fmt.Sprintf(l.Tomorrow, time_)
+	case -7 < days && days < 0:
+		return fmt.Sprintf(l.LastWeekday, l.Weekdays[t.Weekday()], time_)
+	case 0 < days && days < 7:
+		return fmt.Sprintf(l.NextWeekday, l.Weekdays[t.Weekday()], time_)
+	}
+	return FormatTime(l.DateFormat, &t)
+}
+
+// humanize t relative to now
+func humanize(t, now time.Time, opts *HumanizeOptions) string {
+	o := HumanizeOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Parts <= 0 {
+		o.Parts = 1
+	}
+	l := getHumanizeLocale(o.Locale)
+
+	if o.Calendar {
+		return humanizeCalendar(t, now, l)
+	}
+
+	past := t.Before(now)
+	from, to := now, t
+	if past {
+		from, to = t, now
+	}
+	if to.Sub(from) < o.JustNow {
+		return l.JustNow
+	}
+
+	parts := humanizeParts(from, to, &o, true)
+	if len(parts) == 0 {
+		return l.JustNow
+	}
+
+	strs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if o.Short {
+			f, ok := l.ShortUnits[p.unit]
+			if !ok {
+				f = humanizeLocaleEn.ShortUnits[p.unit]
+			}
+			strs = append(strs, fmt.Sprintf(f, p.n))
+			continue
+		}
+		f, ok := l.Units[p.unit]
+		if !ok {
+			f = humanizeLocaleEn.Units[p.unit]
+		}
+		if p.n == 1 {
+			strs = append(strs, fmt.Sprintf(f[0], p.n))
+		} else {
+			strs = append(strs, fmt.Sprintf(f[1], p.n))
+		}
+	}
+	s := strings.Join(strs, l.Separator)
+
+	if past {
+		return fmt.Sprintf(l.Past, s)
+	}
+	return fmt.Sprintf(l.Future, s)
+}
+
+// Humanize t relative to now ("3 days ago", "in 2 hours")
+//
+// If t or now is nil, the current time is used.
+func Humanize(t *time.Time, now *time.Time) string {
+	return HumanizeWithOptions(t, now, nil)
+}
+
+// Humanize t relative to now with options (nil means the current time)
+func HumanizeWithOptions(t *time.Time, now *time.Time, opts *HumanizeOptions) string {
+	now_ := time.Now()
+	if now == nil {
+		now = &now_
+	}
+	if t == nil {
+		t = &now_
+	}
+	return humanize(*t, *now, opts)
+}
@@ -0,0 +1,179 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestHumanize(t *testing.T) {
+	now := time.Date(2021, time.December, 29, 18, 24, 0, 0, time.Local)
+
+	testcases := map[string]time.Time{
+		"just now":       now,
+		"1 second ago":   now.Add(-time.Second),
+		"30 seconds ago": now.Add(-30 * time.Second),
+		"in 2 hours":     now.Add(2 * time.Hour),
+		"3 days ago":     now.AddDate(0, 0, -3),
+		"2 weeks ago":    now.AddDate(0, 0, -15),
+		"in 1 month":     now.AddDate(0, 1, 3),
+		"1 year ago":     now.AddDate(-1, -11, 0),
+	}
+	for expected, tm := range testcases {
+		assert.Equal(t, expected, Humanize(&tm, &now), expected)
+	}
+
+	// nil means now
+	assert.Equal(t, "just now", Humanize(nil, nil))
+	past := time.Now().Add(-3 * time.Hour)
+	assert.Equal(t, "3 hours ago", Humanize(&past, nil))
+	assert.Equal(t, "in 3 hours", Humanize(nil, &past))
+
+	// options
+	tm := now.AddDate(-1, -2, -3).Add(-4 * time.Hour)
+	testcases2 := map[string]HumanizeOptions{
+		"1 year ago":                         {},
+		"1 year 2 months ago":                {Parts: 2},
+		"1 year 2 months 3 days 4 hours ago": {Parts: 4},
+		"14 months ago":                      {MaxUnit: "month"},
+		"1y 2mo 3d ago":                      {Parts: 3, Short: true},
+		"1 year 2 months 3 days ago":         {Parts: 5, MinUnit: "day"},
+	}
+	for expected, opts := range testcases2 {
+		assert.Equal(t, expected, HumanizeWithOptions(&tm, &now, &opts), expected)
+	}
+
+	// rounding
+	tm = now.Add(-(time.Hour + 40*time.Minute))
+	assert.Equal(t, "1 hour ago", HumanizeWithOptions(&tm, &now, &HumanizeOptions{MaxUnit: "hour"}))
+	assert.Equal(t, "2 hours ago", HumanizeWithOptions(&tm, &now, &HumanizeOptions{Rounding: ROUND_NEAREST}))
+	assert.Equal(t, "2 hours ago", HumanizeWithOptions(&tm, &now, &HumanizeOptions{Rounding: ROUND_CEIL}))
+	tm = now.Add(59*time.Minute + 50*time.Second)
+	assert.Equal(t, "in 1 hour", HumanizeWithOptions(&tm, &now, &HumanizeOptions{Parts: 2, MinUnit: "minute", Rounding: ROUND_NEAREST}))
+	tm = now.Add(-20 * time.Second)
+	assert.Equal(t, "just now", HumanizeWithOptions(&tm, &now, &HumanizeOptions{MinUnit: "minute"}))
+	assert.Equal(t, "1 minute ago", HumanizeWithOptions(&tm, &now, &HumanizeOptions{MinUnit: "minute", Rounding: ROUND_CEIL}))
+
+	// just now
+	assert.Equal(t, "just now", HumanizeWithOptions(&tm, &now, &HumanizeOptions{JustNow: time.Minute}))
+
+	// calendar
+	calendar := &HumanizeOptions{Calendar: true}
+	testcases3 := map[string]time.Time{
+		"today at 09:00":       time.Date(2021, time.December, 29, 9, 0, 0, 0, time.Local),
+		"yesterday at 17:00":   time.Date(2021, time.December, 28, 17, 0, 0, 0, time.Local),
+		"tomorrow at 14:30":    time.Date(2021, time.December, 30, 14, 30, 0, 0, time.Local),
+		"last Friday at 14:00": time.Date(2021, time.December, 24, 14, 0, 0, 0, time.Local),
+		"next Monday at 08:00": time.Date(2022, time.January, 3, 8, 0, 0, 0, time.Local),
+		"2021-12-01 10:00":     time.Date(2021, time.December, 1, 10, 0, 0, 0, time.Local),
+	}
+	for expected, tm := range testcases3 {
+		assert.Equal(t, expected, HumanizeWithOptions(&tm, &now, calendar), expected)
+	}
+}
+
+func TestHumanizeRoundTrip(t *testing.T) {
+	now := time.Date(2021, time.December, 29, 18, 24, 0, 0, time.Local)
+
+	testcases := []time.Time{
+		now.Add(-45 * time.Second),
+		now.Add(3 * time.Hour),
+		now.AddDate(0, 0, -3),
+		now.AddDate(0, 2, 0),
+		now.AddDate(-1, -2, 0),
+		now.AddDate(0, 0, -16).Add(-5 * time.Hour),
+	}
+	opts := []*HumanizeOptions{
+		nil,
+		{Parts: 3},
+		{Calendar: true},
+	}
+	for _, tm := range testcases {
+		for _, o := range opts {
+			s := HumanizeWithOptions(&tm, &now, o)
+			if o != nil && o.Calendar {
+				// calendar style has only minutes
+				tm = time.Date(tm.Year(), tm.Month(), tm.Day(), tm.Hour(), tm.Minute(), 0, 0, tm.Location())
+			} else if o == nil || o.Parts < 3 {
+				continue
+			}
+			res, err := ParseTimeStr(s, &now)
+			assert.Nil(t, err, s)
+			if err != nil {
+				continue
+			}
+			assert.Equal(t, tm.Unix(), res.Unix(), s)
+		}
+	}
+
+	// "just now" is the base time
+	s := Humanize(&now, &now)
+	res, err := ParseTimeStr(s, &now)
+	assert.Nil(t, err, s)
+	assert.Equal(t, now.Unix(), res.Unix(), s)
+}
+
+func TestDiffForHumans(t *testing.T) {
+	d1, err := New("2021-12-29 18:24:00")
+	assert.Nil(t, err)
+	d2, err := New("2021-12-26 18:24:00")
+	assert.Nil(t, err)
+
+	assert.Equal(t, "in 3 days", d1.DiffForHumans(d2, nil))
+	assert.Equal(t, "3 days ago", d2.DiffForHumans(d1, nil))
+	assert.Equal(t, "72h ago", d2.DiffForHumans(d1, &HumanizeOptions{MaxUnit: "hour", Short: true}))
+
+	// nil means now
+	d3 := Now()
+	d3.AddSecond(70)
+	assert.Equal(t, "in 1 minute", d3.DiffForHumans(nil, nil))
+}
+
+func TestRegisterLocale(t *testing.T) {
+	RegisterLocale("ja", &HumanizeLocale{
+		Units: map[string][2]string{
+			"day":  {"%d日", "%d日"},
+			"hour": {"%d時間", "%d時間"},
+		},
+		Separator: "",
+		Past:      "%s前",
+		Future:    "%s後",
+		JustNow:   "たった今",
+	})
+	now := time.Date(2021, time.December, 29, 18, 24, 0, 0, time.Local)
+	tm := now.Add(-27 * time.Hour)
+
+	assert.Equal(t, "1日3時間前", HumanizeWithOptions(&tm, &now, &HumanizeOptions{Parts: 2, Locale: "ja"}))
+	assert.Equal(t, "たった今", HumanizeWithOptions(&now, &now, &HumanizeOptions{Locale: "ja"}))
+
+	// empty strings fall back to english
+	RegisterLocale("partial", &HumanizeLocale{
+		Units: map[string][2]string{
+			"day": {"%d jour", "%d jours"},
+		},
+		Separator: " ",
+	})
+	assert.Equal(t, "1 jour ago", HumanizeWithOptions(&tm, &now, &HumanizeOptions{Locale: "partial"}))
+	assert.Equal(t, "just now", HumanizeWithOptions(&now, &now, &HumanizeOptions{Locale: "partial"}))
+	assert.Equal(t, "yesterday at 15:24", HumanizeWithOptions(&tm, &now, &HumanizeOptions{Locale: "partial", Calendar: true}))
+
+	// unknown locale
+	assert.Equal(t, "1 day ago", HumanizeWithOptions(&tm, &now, &HumanizeOptions{Locale: "xx"}))
+}
+
+func ExampleHumanize() {
+	now := time.Now()
+	tm := now.AddDate(0, 0, -3)
+	fmt.Println(Humanize(&tm, &now)) // 3 days ago
+
+	tm = now.Add(2 * time.Hour)
+	fmt.Println(Humanize(&tm, &now)) // in 2 hours
+
+	// options
+	tm = now.AddDate(-1, -2, -3)
+	fmt.Println(HumanizeWithOptions(&tm, &now, &HumanizeOptions{Parts: 2}))              // 1 year 2 months ago
+	fmt.Println(HumanizeWithOptions(&tm, &now, &HumanizeOptions{Parts: 2, Short: true})) // 1y 2mo ago
+	fmt.Println(HumanizeWithOptions(&tm, &now, &HumanizeOptions{MaxUnit: "month"}))      // 14 months ago
+	fmt.Println(HumanizeWithOptions(&tm, &now, &HumanizeOptions{Calendar: true}))        // 2020-10-26 18:24
+}
@@ -0,0 +1,772 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ==============================================================
+// ICU (CLDR) date patterns
+// ==============================================================
+//
+// Patterns like "yyyy-MM-dd'T'HH:mm:ss.SSSXXX" used by ICU, Java's
+// DateTimeFormatter and Elasticsearch.
+// Week-based fields ('Y', 'w', 'e', 'c') follow the ISO 8601 rules
+// (weeks start on Monday and the week 1 contains January 4th).
+
+// a chunk of an ICU pattern
+type icuToken struct {
+	c   byte   // pattern letter (0 means literal text)
+	n   int    // count of the pattern letter
+	lit string // literal text
+}
+
+// split an ICU pattern into pattern letters and literal texts
+func tokenizeICU(pattern string) []icuToken {
+	tokens := make([]icuToken, 0, len(pattern))
+	lit := make([]byte, 0, len(pattern))
+
+	p_len := len(pattern)
+	pos := 0
+	for pos < p_len {
+		c := pattern[pos]
+
+		// 'quoted text' or '' (single quote)
+		if c == '\'' {
+			if pos+1 < p_len && pattern[pos+1] == '\'' {
+				lit = append(lit, '\'')
+				pos += 2
+				continue
+			}
+			pos++
+			for pos < p_len {
+				if pattern[pos] == '\'' {
+					if pos+1 < p_len && pattern[pos+1] == '\'' {
+						lit = append(lit, '\'')
+						pos += 2
+						continue
+					}
+					break
+				}
+				lit = append(lit, pattern[pos])
+				pos++
+			}
+			pos++ // closing quote
+			continue
+		}
+
+		// literal
+		if !(('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')) {
+			lit = append(lit, c)
+			pos++
+			continue
+		}
+
+		// pattern letters
+		if len(lit) > 0 {
+			tokens = append(tokens, icuToken{0, 0, string(lit)})
+			lit = lit[:0]
+		}
+		n := 1
+		for pos+n < p_len && pattern[pos+n] == c {
+			n++
+		}
+		tokens = append(tokens, icuToken{c, n, ""})
+		pos += n
+	}
+	if len(lit) > 0 {
+		tokens = append(tokens, icuToken{0, 0, string(lit)})
+	}
+	return tokens
+}
+
+// check if the token is a numeric field (used to detect adjacent numeric fields like "yyyyMMdd")
+func (tok *icuToken) isNumeric() bool {
+	switch tok.c {
+	case 'y', 'u', 'Y', 'w', 'W', 'd', 'D', 'F', 'h', 'H', 'k', 'K', 'm', 's', 'S', 'A':
+		return true
+	case 'M', 'L', 'Q', 'q', 'e', 'c':
+		return tok.n <= 2
+	}
+	return false
+}
+
+// ==============================================================
+// format
+// ==============================================================
+
+// append an ISO 8601 offset like +09, +0900, +09:00 or +09:00:15
+func appendISOOffset(dst []byte, offset int, colon bool, minutes bool, seconds bool) []byte {
+	if offset < 0 {
+		dst = append(dst, '-')
+		offset = -offset
+	} else {
+		dst = append(dst, '+')
+	}
+	dst = appendInt(dst, offset/3600, 2)
+	if minutes || offset%3600 != 0 {
+		if colon {
+			dst = append(dst, ':')
+		}
+		dst = appendInt(dst, (offset/60)%60, 2)
+	}
+	if seconds && offset%60 != 0 {
+		if colon {
+			dst = append(dst, ':')
+		}
+		dst = appendInt(dst, offset%60, 2)
+	}
+	return dst
+}
+
+// append a localized GMT format like GMT+9 or GMT+09:00
+func appendGMTOffset(dst []byte, offset int, long bool) []byte {
+	dst = append(dst, "GMT"...)
+	if offset == 0 {
+		return dst
+	}
+	if offset < 0 {
+		dst = append(dst, '-')
+		offset = -offset
+	} else {
+		dst = append(dst, '+')
+	}
+	if long {
+		dst = appendInt(dst, offset/3600, 2)
+		dst = append(dst, ':')
+		return appendInt(dst, (offset/60)%60, 2)
+	}
+	dst = appendInt(dst, offset/3600, 1)
+	if offset%3600 != 0 {
+		dst = append(dst, ':')
+		dst = appendInt(dst, (offset/60)%60, 2)
+	}
+	return dst
+}
+
+func icuFormatToken(dst []byte, tok *icuToken, d *time.Time) []byte {
+	n := tok.n
+	switch tok.c {
+	// Era
+	case 'G':
+		ad := d.Year() > 0
+		switch {
+		case n == 4 && ad:
+			return append(dst, "Anno Domini"...)
+		case n == 4:
+			return append(dst, "Before Christ"...)
+		case n == 5 && ad:
+			return append(dst, 'A')
+		case n == 5:
+			return append(dst, 'B')
+		case ad:
+			return append(dst, "AD"...)
+		default:
+			return append(dst, "BC"...)
+		}
+
+	// Year
+	case 'y', 'u', 'Y':
+		y := d.Year()
+		if tok.c == 'Y' {
+			y, _ = d.ISOWeek()
+		} else if tok.c == 'y' && y <= 0 {
+			y = 1 - y // year of era
+		}
+		if n == 2 {
+			return appendInt(dst, (y%100+100)%100, 2)
+		}
+		return appendInt(dst, y, n)
+
+	// Quarter
+	case 'Q', 'q':
+		q := (int(d.Month())-1)/3 + 1
+		switch {
+		case n <= 2:
+			return appendInt(dst, q, n)
+		case n == 3:
+			return appendInt(append(dst, 'Q'), q, 1)
+		default:
+			dst = appendInt(dst, q, 1)
+			dst = append(dst, ordinalSuffix(q)...)
+			return append(dst, " quarter"...)
+		}
+
+	// Month
+	case 'M', 'L':
+		switch {
+		case n <= 2:
+			return appendInt(dst, int(d.Month()), n)
+		case n == 3:
+			return append(dst, d.Month().String()[0:3]...)
+		case n == 4:
+			return append(dst, d.Month().String()...)
+		default:
+			return append(dst, d.Month().String()[0])
+		}
+
+	// Week
+	case 'w':
+		_, w := d.ISOWeek()
+		return appendInt(dst, w, n)
+	case 'W':
+		first := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+		offset := (int(first.Weekday()) + 6) % 7
+		return appendInt(dst, (d.Day()-1+offset)/7+1, n)
+
+	// Day
+	case 'd':
+		return appendInt(dst, d.Day(), n)
+	case 'D':
+		return appendInt(dst, d.YearDay(), n)
+	case 'F':
+		return appendInt(dst, (d.Day()-1)/7+1, n)
+
+	// Weekday
+	case 'e', 'c':
+		if n <= 2 {
+			return appendInt(dst, (int(d.Weekday())+6)%7+1, n)
+		}
+		fallthrough
+	case 'E':
+		switch {
+		case n == 4:
+			return append(dst, d.Weekday().String()...)
+		case n == 5:
+			return append(dst, d.Weekday().String()[0])
+		case n == 6:
+			return append(dst, d.Weekday().String()[0:2]...)
+		default:
+			return append(dst, d.Weekday().String()[0:3]...)
+		}
+
+	// AM/PM
+	case 'a':
+		if d.Hour() < 12 {
+			return append(dst, "AM"...)
+		}
+		return append(dst, "PM"...)
+
+	// Hour
+	case 'h':
+		h := d.Hour() % 12
+		if h == 0 {
+			h = 12
+		}
+		return appendInt(dst, h, n)
+	case 'H':
+		return appendInt(dst, d.Hour(), n)
+	case 'k':
+		h := d.Hour()
+		if h == 0 {
+			h = 24
+		}
+		return appendInt(dst, h, n)
+	case 'K':
+		return appendInt(dst, d.Hour()%12, n)
+
+	// Minute / Second
+	case 'm':
+		return appendInt(dst, d.Minute(), n)
+	case 's':
+		return appendInt(dst, d.Second(), n)
+	case 'S':
+		frac := appendInt(make([]byte, 0, 9), d.Nanosecond(), 9)
+		if n <= 9 {
+			return append(dst, frac[0:n]...)
+		}
+		dst = append(dst, frac...)
+		for i := 9; i < n; i++ {
+			dst = append(dst, '0')
+		}
+		return dst
+	case 'A':
+		ms := ((d.Hour()*60+d.Minute())*60+d.Second())*1000 + d.Nanosecond()/1e6
+		return appendInt(dst, ms, n)
+
+	// Timezone
+	case 'z':
+		name_, offset_ := d.Zone()
+		if n < 4 && name_ != "" && name_[0] != '+' && name_[0] != '-' {
+			return append(dst, name_...)
+		}
+		return appendGMTOffset(dst, offset_, n >= 4)
+	case 'Z':
+		_, offset_ := d.Zone()
+		switch {
+		case n == 4:
+			return appendGMTOffset(dst, offset_, true)
+		case n == 5 && offset_ == 0:
+			return append(dst, 'Z')
+		case n == 5:
+			return appendISOOffset(dst, offset_, true, true, true)
+		default:
+			return appendISOOffset(dst, offset_, false, true, false)
+		}
+	case 'O':
+		_, offset_ := d.Zone()
+		return appendGMTOffset(dst, offset_, n >= 4)
+	case 'V':
+		return append(dst, d.Location().String()...)
+	case 'X', 'x':
+		_, offset_ := d.Zone()
+		if tok.c == 'X' && offset_ == 0 {
+			return append(dst, 'Z')
+		}
+		switch n {
+		case 1:
+			return appendISOOffset(dst, offset_, false, false, false)
+		case 2:
+			return appendISOOffset(dst, offset_, false, true, false)
+		case 3:
+			return appendISOOffset(dst, offset_, true, true, false)
+		case 4:
+			return appendISOOffset(dst, offset_, false, true, true)
+		default:
+			return appendISOOffset(dst, offset_, true, true, true)
+		}
+	}
+
+	// unknown pattern letters are copied as they are
+	for i := 0; i < n; i++ {
+		dst = append(dst, tok.c)
+	}
+	return dst
+}
+
+// Format a time.Time variable to a string with an ICU (CLDR) pattern
+func FormatICU(pattern string, dt *time.Time) string {
+	if dt == nil {
+		d := time.Now()
+		dt = &d
+	}
+
+	tokens := tokenizeICU(pattern)
+
+	dst := make([]byte, 0, len(pattern)+16)
+	for i := range tokens {
+		if tokens[i].c == 0 {
+			dst = append(dst, tokens[i].lit...)
+		} else {
+			dst = icuFormatToken(dst, &tokens[i], dt)
+		}
+	}
+	return string(dst)
+}
+
+// ==============================================================
+// parse
+// ==============================================================
+
+// parse an offset like Z, +09, +0900 or +09:00 (with an optional "GMT" prefix)
+func parseICUOffset(s *string, pos_s *int, allow_z bool) (int, bool) {
+	s_len := len(*s)
+	pos := *pos_s
+
+	gmt := false
+	if cmpiStartWith((*s)[pos:], "GMT") || cmpiStartWith((*s)[pos:], "UTC") {
+		gmt = true
+		pos += 3
+	}
+	if pos >= s_len || ((*s)[pos] != '+' && (*s)[pos] != '-') {
+		if gmt {
+			*pos_s = pos
+			return 0, true
+		}
+		if allow_z && pos < s_len && ((*s)[pos] == 'Z' || (*s)[pos] == 'z') {
+			*pos_s = pos + 1
+			return 0, true
+		}
+		return -1, false
+	}
+	sign_ := 1
+	if (*s)[pos] == '-' {
+		sign_ = -1
+	}
+	pos++
+
+	// hours
+	h_, ok := parseInt(s, &pos, 1, 2)
+	if !ok {
+		return -1, false
+	}
+	// minutes and seconds
+	m_ := 0
+	sec_ := 0
+	for i := 0; i < 2; i++ {
+		p_ := pos
+		if p_ < s_len && (*s)[p_] == ':' {
+			p_++
+		}
+		if p_+1 < s_len && isNumeric((*s)[p_]) && isNumeric((*s)[p_+1]) {
+			n_, _ := parseInt(s, &p_, 2, 2)
+			if i == 0 {
+				m_ = n_
+			} else {
+				sec_ = n_
+			}
+			pos = p_
+		} else {
+			break
+		}
+	}
+	if h_ > 23 || m_ > 59 || sec_ > 59 {
+		return -1, false
+	}
+	*pos_s = pos
+	return ((h_*60+m_)*60 + sec_) * sign_, true
+}
+
+// parse one of the words (case insensitive) and return its index
+func parseICUWords(s *string, pos_s *int, words []string) (int, bool) {
+	for i, w := range words {
+		if cmpiStartWith((*s)[*pos_s:], w) {
+			*pos_s += len(w)
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// fields which are resolved after all tokens are parsed (shared with Moment.js)
+type deferredFields struct {
+	bc                  bool // era
+	ap                  int  // AM/PM
+	q                   int  // quarter
+	yday                int  // day of year
+	iso_y, iso_w, iso_d int  // ISO week date
+	loc_y, loc_w, loc_d int  // locale week date (Moment.js)
+}
+
+func newDeferredFields() *deferredFields {
+	return &deferredFields{false, 0, -1, -1, -1, -1, -1, -1, -1, -1}
+}
+
+// apply the deferred fields to data
+// (missing leading date fields are taken from base, nil means now)
+func (f *deferredFields) apply(data *TimeData, base *time.Time) error {
+	// era
+	if f.bc {
+		data.setYear(1 - data.y)
+	}
+	// missing date fields
+	extra := 0
+	if f.yday > 0 || f.iso_w >= 0 || f.loc_w >= 0 {
+		extra |= SET_MONTH | SET_DAY
+	} else if f.q > 0 {
+		extra |= SET_MONTH
+	}
+	if f.iso_y >= 0 || f.loc_y >= 0 {
+		extra |= SET_YEAR | SET_MONTH | SET_DAY
+	}
+	data.fillUnsetDate(base, extra)
+	// day of year (after the year is parsed)
+	if f.yday > 0 {
+		if f.yday == 366 && !checkDate(data.y, 2, 29) {
+			return errors.New(fmt.Sprintf("failed to parse: day of year %d in %d", f.yday, data.y))
+		}
+		data.setMonth(1)
+		data.setDay(f.yday)
+		data.normalizeYmd()
+	}
+	// quarter
+	if f.q > 0 && !data.hasFlag(SET_MONTH) {
+		data.setMonth((f.q-1)*3 + 1)
+	}
+	// week date
+	if f.iso_y >= 0 || f.iso_w >= 0 {
+		iso_y, iso_w, iso_d := f.iso_y, f.iso_w, f.iso_d
+		if iso_y < 0 {
+			iso_y = data.y
+		}
+		if iso_w < 0 {
+			iso_w = 1
+		}
+		if iso_d < 0 {
+			iso_d = 1
+		}
+		y_, m_, d_ := isoWeekDate(iso_y, iso_w, iso_d)
+		data.setYear(y_)
+		data.setMonth(m_)
+		data.setDay(d_)
+	} else if f.loc_y >= 0 || f.loc_w >= 0 {
+		loc_y, loc_w, loc_d := f.loc_y, f.loc_w, f.loc_d
+		if loc_y < 0 {
+			loc_y = data.y
+		}
+		if loc_w < 0 {
+			loc_w = 1
+		}
+		if loc_d < 0 {
+			loc_d = 0
+		}
+		t_ := momentLocaleWeekStart(loc_y).AddDate(0, 0, (loc_w-1)*7+loc_d)
+		data.setYear(t_.Year())
+		data.setMonth(int(t_.Month()))
+		data.setDay(t_.Day())
+	}
+	// AM/PM
+	if f.ap == PM && data.h < 12 {
+		data.h += 12
+	} else if f.ap == AM && data.h == 12 {
+		data.h = 0
+	}
+	return nil
+}
+
+// Convert a datetime string to a time.Time variable with an ICU (CLDR) pattern
+//
+// Missing leading date fields are taken from the current date ("MM-dd" is in this year)
+// and trailing data is an error like ICU and Java.
+func ParseICU(pattern string, s string) (*time.Time, error) {
+	return ParseICUWithBase(pattern, s, nil)
+}
+
+// same as ParseICU but missing leading date fields are taken from base (nil means now)
+func ParseICUWithBase(pattern string, s string, base *time.Time) (*time.Time, error) {
+	data, err := ParseICUData(pattern, s, base)
+	if err != nil {
+		return nil, err
+	}
+	return data.resolve()
+}
+
+// Convert a datetime string to a TimeData variable with an ICU (CLDR) pattern
+// (missing leading date fields are taken from base, nil means now)
+func ParseICUData(pattern string, s string, base *time.Time) (*TimeData, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty data")
+	}
+
+	tokens := tokenizeICU(pattern)
+	data := newTimeData()
+
+	s_len := len(s)
+	pos_s := 0
+
+	df := newDeferredFields()
+
+	for i := range tokens {
+		tok := &tokens[i]
+
+		// literal
+		if tok.c == 0 {
+			lit := tok.lit
+			for j := 0; j < len(lit); j++ {
+				if isSpace(lit[j]) {
+					_ = skipSpaces(&s, &pos_s)
+					continue
+				}
+				if pos_s >= s_len || s[pos_s] != lit[j] {
+					return nil, errors.New(fmt.Sprintf("failed to parse pattern: '%s'", lit))
+				}
+				pos_s++
+			}
+			continue
+		}
+
+		if pos_s >= s_len {
+			return nil, errors.New(fmt.Sprintf("failed to parse pattern: %s", strings.Repeat(string(tok.c), tok.n)))
+		}
+
+		// width of numeric fields
+		min_, max_ := 1, 2
+		if tok.n > 1 {
+			min_, max_ = tok.n, tok.n
+		}
+		adjacent := i+1 < len(tokens) && tokens[i+1].isNumeric()
+
+		n := 0
+		ok := true
+		switch tok.c {
+		// Era
+		case 'G':
+			var idx int
+			if idx, ok = parseICUWords(&s, &pos_s, []string{"anno domini", "before christ", "ad", "bc", "a", "b"}); ok {
+				df.bc = idx%2 == 1
+			}
+
+		// Year
+		case 'y', 'u', 'Y':
+			if tok.n == 2 {
+				if n, ok = parseInt(&s, &pos_s, 2, 2); ok {
+					if n < 70 {
+						n += 2000
+					} else {
+						n += 1900
+					}
+				}
+			} else {
+				if !adjacent {
+					max_ = 9
+				} else if tok.n == 1 {
+					max_ = 4
+				}
+				n, ok = parseInt(&s, &pos_s, min_, max_)
+			}
+			if ok {
+				if tok.c == 'Y' {
+					df.iso_y = n
+				} else {
+					data.setYear(n)
+				}
+			}
+
+		// Quarter
+		case 'Q', 'q':
+			switch {
+			case tok.n <= 2:
+				// "4" or "04"
+				df.q, ok = parseInt(&s, &pos_s, min_, min_)
+			case tok.n == 3:
+				if ok = pos_s < s_len && (s[pos_s] == 'Q' || s[pos_s] == 'q'); ok {
+					pos_s++
+					df.q, ok = parseInt(&s, &pos_s, 1, 1)
+				}
+			default:
+				if df.q, ok = parseInt(&s, &pos_s, 1, 1); ok && parseSuffix(&s, &pos_s) {
+					_ = skipSpaces(&s, &pos_s)
+					_, ok = parseICUWords(&s, &pos_s, []string{"quarter"})
+				} else {
+					ok = false
+				}
+			}
+			ok = ok && 1 <= df.q && df.q <= 4
+
+		// Month
+		case 'M', 'L':
+			if tok.n <= 2 {
+				n, ok = parseInt(&s, &pos_s, min_, max_)
+				ok = ok && 1 <= n && n <= 12
+			} else {
+				n, ok = parseMonth(&s, &pos_s)
+			}
+			if ok {
+				data.setMonth(n)
+			}
+
+		// Week
+		case 'w':
+			if df.iso_w, ok = parseInt(&s, &pos_s, min_, max_); ok {
+				ok = 1 <= df.iso_w && df.iso_w <= 53
+			}
+
+		// Day
+		case 'd':
+			if n, ok = parseInt(&s, &pos_s, min_, max_); ok {
+				ok = 1 <= n && n <= 31
+				data.setDay(n)
+			}
+		case 'D':
+			if tok.n == 1 {
+				max_ = 3
+			}
+			if df.yday, ok = parseInt(&s, &pos_s, min_, max_); ok {
+				ok = 1 <= df.yday && df.yday <= 366
+			}
+
+		// Weekday
+		case 'e', 'c':
+			if tok.n <= 2 {
+				if df.iso_d, ok = parseInt(&s, &pos_s, min_, min_); ok {
+					ok = 1 <= df.iso_d && df.iso_d <= 7
+				}
+				break
+			}
+			fallthrough
+		case 'E':
+			if n, ok = parseWeekday(&s, &pos_s); ok {
+				df.iso_d = (n+6)%7 + 1
+				data.day = n
+			}
+
+		// AM/PM
+		case 'a':
+			df.ap, ok = parseAMPM(&s, &pos_s)
+
+		// Hour
+		case 'h', 'H', 'k', 'K':
+			if n, ok = parseInt(&s, &pos_s, min_, max_); ok {
+				switch tok.c {
+				case 'h':
+					ok = 1 <= n && n <= 12
+				case 'H':
+					ok = 0 <= n && n <= 23
+				case 'k':
+					ok = 1 <= n && n <= 24
+					n %= 24
+				case 'K':
+					ok = 0 <= n && n <= 11
+				}
+				data.setHour(n)
+			}
+
+		// Minute / Second
+		case 'm':
+			if n, ok = parseInt(&s, &pos_s, min_, max_); ok {
+				ok = n <= 59
+				data.setMinute(n)
+			}
+		case 's':
+			if n, ok = parseInt(&s, &pos_s, min_, max_); ok {
+				ok = n <= 59
+				data.setSecond(n)
+			}
+		case 'S':
+			if adjacent {
+				max_ = tok.n
+			} else {
+				max_ = 9
+			}
+			var f float64
+			if f, ok = parseDecimal(&s, &pos_s, min_, max_); ok {
+				data.setNanosecond(int(f*1e9 + 0.5))
+			}
+
+		// Timezone
+		case 'X', 'x', 'Z', 'O':
+			zone_s := pos_s
+			if n, ok = parseICUOffset(&s, &pos_s, tok.c != 'x'); ok {
+				data.setFixedZone(n)
+				data.zone = s[zone_s:pos_s]
+			}
+		case 'z', 'V':
+			zone_s := pos_s
+			if n, ok = parseICUOffset(&s, &pos_s, true); ok {
+				data.setFixedZone(n)
+				data.zone = s[zone_s:pos_s]
+				break
+			}
+			loc, ok_, err_ := parseLocation(&s, &pos_s)
+			if err_ != nil {
+				return nil, err_
+			}
+			if ok = ok_; ok {
+				data.setLocation(loc)
+				data.zone = s[zone_s:pos_s]
+			}
+
+		default:
+			return nil, errors.New(fmt.Sprintf("unsupported pattern letter: %s", string(tok.c)))
+		}
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("failed to parse pattern: %s", strings.Repeat(string(tok.c), tok.n)))
+		}
+	}
+
+	if pos_s < s_len {
+		return nil, errors.New(fmt.Sprintf("failed to parse pattern: trailing data '%s'", s[pos_s:]))
+	}
+
+	if err := df.apply(data, base); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
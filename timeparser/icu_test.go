@@ -0,0 +1,230 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFormatICU(t *testing.T) {
+	utc, err := time.LoadLocation("UTC")
+	assert.Nil(t, err)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.Nil(t, err)
+
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, utc)
+
+	testcases := map[string]string{
+		// general format
+		"yyyy-MM-dd'T'HH:mm:ss.SSSXXX": "2021-12-29T18:24:36.123Z",
+		"EEE, d MMM yyyy HH:mm:ss Z":   "Wed, 29 Dec 2021 18:24:36 +0000",
+		"EEEE, MMMM d, y 'at' h:mm a":  "Wednesday, December 29, 2021 at 6:24 PM",
+		"yy/M/d":                       "21/12/29",
+		"''yy'' 'o''clock'":            "'21' o'clock",
+
+		// Each pattern letter
+		"G GGGG GGGGG":           "AD Anno Domini A",
+		"Q QQ QQQ QQQQ":          "4 04 Q4 4th quarter",
+		"M MM MMM MMMM MMMMM":    "12 12 Dec December D",
+		"YYYY-'W'ww-e":           "2021-W52-3",
+		"d dd D DDD F":           "29 29 363 363 5",
+		"E EEEE EEEEE EEEEEE":    "Wed Wednesday W We",
+		"k K h H":                "18 6 6 18",
+		"m mm s ss":              "24 24 36 36",
+		"S SS SSSSSS SSSSSSSSSS": "1 12 123456 1234567890",
+		"A":                      "66276123",
+		"X XX XXX x xx xxx":      "Z Z Z +00 +0000 +00:00",
+		"Z ZZZZ ZZZZZ O OOOO":    "+0000 GMT Z GMT GMT",
+		"z VV":                   "UTC UTC",
+	}
+	for pattern, expected := range testcases {
+		assert.Equal(t, expected, FormatICU(pattern, &tm), pattern)
+	}
+
+	// Timezone
+	tm = time.Date(2021, time.December, 29, 18, 24, 36, 123456789, tokyo)
+	testcases = map[string]string{
+		"yyyy-MM-dd'T'HH:mm:ss.SSSXXX": "2021-12-29T18:24:36.123+09:00",
+		"X XX XXX x xx xxx":            "+09 +0900 +09:00 +09 +0900 +09:00",
+		"Z ZZZZ ZZZZZ O OOOO":          "+0900 GMT+09:00 +09:00 GMT+9 GMT+09:00",
+		"z zzzz VV":                    "JST GMT+09:00 Asia/Tokyo",
+	}
+	for pattern, expected := range testcases {
+		assert.Equal(t, expected, FormatICU(pattern, &tm), pattern)
+	}
+
+	// Week based year
+	tm = time.Date(2021, time.January, 1, 0, 0, 0, 0, utc)
+	assert.Equal(t, "2020-W53-5 2021", FormatICU("YYYY-'W'ww-e yyyy", &tm))
+	assert.Equal(t, "12 AM", FormatICU("h a", &tm))
+}
+
+func TestParseICU(t *testing.T) {
+	expected := time.Date(2021, time.December, 29, 18, 24, 36, 0, time.Local)
+	testcases := map[string]string{
+		"EEEE, MMMM d, y 'at' h:mm:ss a": "Wednesday, December 29, 2021 at 6:24:36 PM",
+		"yyyyMMddHHmmss":                 "20211229182436",
+		"dd.MM.yy HH:mm:ss":              "29.12.21 18:24:36",
+		"YYYY-'W'ww-e HH:mm:ss":          "2021-W52-3 18:24:36",
+		"D yyyy k:m:s":                   "363 2021 18:24:36",
+	}
+	for pattern, s := range testcases {
+		tm, err := ParseICU(pattern, s)
+
+		assert.Nil(t, err, pattern)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, expected.Year(), tm.Year(), pattern)
+		assert.Equal(t, expected.Month(), tm.Month(), pattern)
+		assert.Equal(t, expected.Day(), tm.Day(), pattern)
+		assert.Equal(t, expected.Hour(), tm.Hour(), pattern)
+		assert.Equal(t, expected.Minute(), tm.Minute(), pattern)
+		assert.Equal(t, expected.Second(), tm.Second(), pattern)
+		assert.Equal(t, expected.Location(), tm.Location(), pattern)
+	}
+
+	// quoted literals
+	tm, err := ParseICU("hh 'o''clock' a, d MMM yyyy", "06 o'clock PM, 29 Dec 2021")
+	assert.Nil(t, err)
+	assert.Equal(t, 18, tm.Hour())
+	assert.Equal(t, 29, tm.Day())
+
+	// fractions and quarters
+	tm, err = ParseICU("yyyy-MM-dd'T'HH:mm:ss.SSS", "2021-10-05T01:02:03.123")
+	assert.Nil(t, err)
+	assert.Equal(t, time.October, tm.Month())
+	assert.Equal(t, 123000000, tm.Nanosecond())
+
+	tm, err = ParseICU("QQQ yyyy", "Q3 2021")
+	assert.Nil(t, err)
+	assert.Equal(t, time.July, tm.Month())
+	assert.Equal(t, 1, tm.Day())
+
+	// day of year in a leap year
+	tm, err = ParseICU("D yyyy", "060 2020")
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-02-29", FormatTime("Y-m-d", tm))
+
+	tm, err = ParseICU("DDD yyyy", "366 2020")
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-12-31", FormatTime("Y-m-d", tm))
+
+	_, err = ParseICU("DDD yyyy", "366 2021")
+	assert.NotNil(t, err)
+
+	tm, err = ParseICU("QQ yyyy", "04 2021")
+	assert.Nil(t, err)
+	assert.Equal(t, time.October, tm.Month())
+
+	tm, err = ParseICU("Q yyyy", "2 2021")
+	assert.Nil(t, err)
+	assert.Equal(t, time.April, tm.Month())
+
+	tm, err = ParseICU("MMMM d, y G", "March 15, 44 BC")
+	assert.Nil(t, err)
+	assert.Equal(t, -43, tm.Year())
+
+	// timezones are parsed in the same way as ParseFormat()
+	tm, err = ParseICU("yyyy-MM-dd'T'HH:mm:ssXXX", "2021-12-29T18:24:36+09:00")
+	assert.Nil(t, err)
+	tm2, err := ParseFormat("Y-m-d\\TH:i:sP", "2021-12-29T18:24:36+09:00")
+	assert.Nil(t, err)
+	assert.Equal(t, tm2.Unix(), tm.Unix())
+
+	tm, err = ParseICU("yyyy-MM-dd HH:mm VV", "2021-12-29 18:24 Asia/Tokyo")
+	assert.Nil(t, err)
+	assert.Equal(t, "Asia/Tokyo", tm.Location().String())
+	assert.Equal(t, 18, tm.Hour())
+
+	// errors
+	errorcases := map[string]string{
+		"yyyy-MM-dd": "2021/12/29",
+		"MM":         "13",
+		"HH:mm":      "24:00",
+		"yyyy J":     "2021 x",
+		"EEEE":       "Someday",
+
+		// trailing data
+		"MM/dd/yyyy":         "12/29/2021garbage",
+		"yyyy-MM-dd'T'HH:mm": "2021-12-29T18:24:36",
+	}
+	for pattern, s := range errorcases {
+		_, err := ParseICU(pattern, s)
+		assert.NotNil(t, err, pattern)
+	}
+	_, err = ParseICU("yyyy-MM-dd", "2021-12-29garbage")
+	assert.NotNil(t, err)
+}
+
+func TestICURoundTrip(t *testing.T) {
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 0, time.UTC)
+	patterns := []string{
+		"yyyy-MM-dd e",
+		"yyyy-MM-dd ee",
+		"yyyy-MM-dd c",
+		"yyyy-MM-dd cc",
+		"YYYY-'W'ww-e HH:mm:ss",
+		"YYYY-'W'ww-ee HH:mm:ss",
+		"YYYY-'W'ww-cc HH:mm:ss",
+	}
+	for _, pattern := range patterns {
+		s := FormatICU(pattern, &tm)
+		res, err := ParseICU(pattern, s)
+		assert.Nil(t, err, pattern)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, s, FormatICU(pattern, res), pattern)
+		assert.Equal(t, 29, res.Day(), pattern)
+	}
+}
+
+func TestParseICUWithBase(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.Nil(t, err)
+	base := time.Date(2000, time.September, 10, 11, 22, 33, 444, tokyo)
+
+	// missing leading date fields are taken from base
+	testcases := []struct {
+		format   string
+		s        string
+		expected string
+	}{
+		{"MM-dd", "12-29", "2000-12-29 00:00:00 +0900 JST"},
+		{"dd", "29", "2000-09-29 00:00:00 +0900 JST"},
+		{"yyyy", "2021", "2021-01-01 00:00:00 +0900 JST"},
+		{"HH:mm", "18:24", "2000-09-10 18:24:00 +0900 JST"},
+		{"D", "60", "2000-02-29 00:00:00 +0900 JST"},
+		{"Q", "3", "2000-07-01 00:00:00 +0900 JST"},
+	}
+	for _, tc := range testcases {
+		tm, err := ParseICUWithBase(tc.format, tc.s, &base)
+		assert.Nil(t, err, tc.format)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc.expected, tm.String(), tc.format)
+	}
+
+	// nil means now
+	tm, err := ParseICU("MM-dd", "12-29")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Now().Year(), tm.Year())
+	assert.Equal(t, 29, tm.Day())
+}
+
+func ExampleFormatICU() {
+	tm := time.Now()
+	fmt.Println(FormatICU("yyyy-MM-dd'T'HH:mm:ss.SSSXXX", &tm)) // 2021-12-29T18:24:00.000+09:00
+	fmt.Println(FormatICU("EEEE, MMMM d, y 'at' h:mm a", &tm))  // Wednesday, December 29, 2021 at 6:24 PM
+}
+func ExampleParseICU() {
+	// 2021-12-29 18:24:12 +0900 JST
+	tm, err := ParseICU("EEEE, MMMM d, y 'at' h:mm:ss a", "Wednesday, December 29, 2021 at 6:24:12 PM")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(tm)
+}
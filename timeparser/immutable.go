@@ -0,0 +1,363 @@
+package timeparser
+
+import (
+	"time"
+)
+
+// ==============================================================
+// Immutable TimeData
+// ==============================================================
+
+// ImmutableTimeData is a TimeData which is never modified.
+//
+// Setters and Add* / Sub* methods return a new value instead of modifying the receiver,
+// so it can be shared between goroutines and chained like
+// `data.AddDay(3).SetDay(1).In(loc)` (like PHP's DateTimeImmutable).
+type ImmutableTimeData struct {
+	data *TimeData
+}
+
+// create a new ImmutableTimeData variable from string format
+func NewImmutable(format string) (ImmutableTimeData, error) {
+	data, err := New(format)
+	if err != nil {
+		return ImmutableTimeData{}, err
+	}
+	return ImmutableTimeData{data}, nil
+}
+
+// create an immutable copy of the TimeData variable
+func (data *TimeData) Immutable() ImmutableTimeData {
+	return ImmutableTimeData{data.Clone()}
+}
+
+// create a mutable copy
+func (t ImmutableTimeData) Mutable() *TimeData {
+	return t.get().Clone()
+}
+
+// the internal TimeData (must not be modified)
+func (t ImmutableTimeData) get() *TimeData {
+	if t.data == nil {
+		return newTimeData()
+	}
+	return t.data
+}
+
+// apply f to a copy
+func (t ImmutableTimeData) with(f func(data *TimeData)) ImmutableTimeData {
+	data := t.Mutable()
+	f(data)
+	return ImmutableTimeData{data}
+}
+
+// ============================================================
+// setter
+// ============================================================
+
+func (t ImmutableTimeData) SetYear(y int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetYear(y) })
+}
+func (t ImmutableTimeData) SetMonth(m int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetMonth(m) })
+}
+func (t ImmutableTimeData) SetDay(d int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetDay(d) })
+}
+func (t ImmutableTimeData) SetHour(h int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetHour(h) })
+}
+func (t ImmutableTimeData) SetMinute(i int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetMinute(i) })
+}
+func (t ImmutableTimeData) SetSecond(s int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetSecond(s) })
+}
+func (t ImmutableTimeData) SetMillisecond(ms int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetMillisecond(ms) })
+}
+func (t ImmutableTimeData) SetMicrosecond(us int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetMicrosecond(us) })
+}
+func (t ImmutableTimeData) SetNanosecond(ns int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetNanosecond(ns) })
+}
+func (t ImmutableTimeData) SetTimezoneOffset(z int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetTimezoneOffset(z) })
+}
+func (t ImmutableTimeData) SetTimezoneOffsetKeepWallClock(z int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetTimezoneOffsetKeepWallClock(z) })
+}
+func (t ImmutableTimeData) SetISOWeek(y int, w int, d int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetISOWeek(y, w, d) })
+}
+func (t ImmutableTimeData) SetDayOfYear(n int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetDayOfYear(n) })
+}
+
+// same as TimeData.In (the wall clock is kept)
+func (t ImmutableTimeData) In(loc *time.Location) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetLocation(loc) })
+}
+
+// same as TimeData.WithLocationKeepWallClock
+func (t ImmutableTimeData) WithLocationKeepWallClock(loc *time.Location) ImmutableTimeData {
+	return t.In(loc)
+}
+
+// same as TimeData.ConvertTo (the instant is kept)
+func (t ImmutableTimeData) ConvertTo(loc *time.Location) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.ConvertTo(loc) })
+}
+
+// same as TimeData.AsUTC
+func (t ImmutableTimeData) AsUTC() ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetUTC() })
+}
+
+// ============================================================
+// getter
+// ============================================================
+
+func (t ImmutableTimeData) GetYear() int {
+	return t.get().GetYear()
+}
+func (t ImmutableTimeData) GetMonth() int {
+	return t.get().GetMonth()
+}
+func (t ImmutableTimeData) GetQuarter() int {
+	return t.get().GetQuarter()
+}
+func (t ImmutableTimeData) GetDay() int {
+	return t.get().GetDay()
+}
+func (t ImmutableTimeData) GetHour() int {
+	return t.get().GetHour()
+}
+func (t ImmutableTimeData) GetMinute() int {
+	return t.get().GetMinute()
+}
+func (t ImmutableTimeData) GetSecond() int {
+	return t.get().GetSecond()
+}
+func (t ImmutableTimeData) GetMillisecond() int {
+	return t.get().GetMillisecond()
+}
+func (t ImmutableTimeData) GetMicrosecond() int {
+	return t.get().GetMicrosecond()
+}
+func (t ImmutableTimeData) GetNanosecond() int {
+	return t.get().GetNanosecond()
+}
+func (t ImmutableTimeData) GetTimezoneOffset() int {
+	return t.get().GetTimezoneOffset()
+}
+func (t ImmutableTimeData) GetLocation() *time.Location {
+	return t.get().GetLocation()
+}
+func (t ImmutableTimeData) GetOffset() int {
+	return t.get().GetOffset()
+}
+func (t ImmutableTimeData) OriginalZoneText() string {
+	return t.get().OriginalZoneText()
+}
+func (t ImmutableTimeData) GetWeekday() time.Weekday {
+	return t.get().GetWeekday()
+}
+func (t ImmutableTimeData) GetISOWeekday() int {
+	return t.get().GetISOWeekday()
+}
+func (t ImmutableTimeData) GetDayOfYear() int {
+	return t.get().GetDayOfYear()
+}
+func (t ImmutableTimeData) GetISOWeek() int {
+	return t.get().GetISOWeek()
+}
+func (t ImmutableTimeData) GetISOYear() int {
+	return t.get().GetISOYear()
+}
+func (t ImmutableTimeData) GetWeekOfMonth() int {
+	return t.get().GetWeekOfMonth()
+}
+func (t ImmutableTimeData) DaysInMonth() int {
+	return t.get().DaysInMonth()
+}
+func (t ImmutableTimeData) DaysInYear() int {
+	return t.get().DaysInYear()
+}
+func (t ImmutableTimeData) IsLeapYear() bool {
+	return t.get().IsLeapYear()
+}
+func (t ImmutableTimeData) IsWeekend() bool {
+	return t.get().IsWeekend()
+}
+func (t ImmutableTimeData) NextTransition() (Transition, bool) {
+	return t.get().NextTransition()
+}
+func (t ImmutableTimeData) PrevTransition() (Transition, bool) {
+	return t.get().PrevTransition()
+}
+
+// ============================================================
+// Add / Sub
+// ============================================================
+
+func (t ImmutableTimeData) AddYear(y int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddYear(y) })
+}
+func (t ImmutableTimeData) AddMonth(m int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddMonth(m) })
+}
+func (t ImmutableTimeData) AddQuarter(q int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddQuarter(q) })
+}
+func (t ImmutableTimeData) AddDay(d int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddDay(d) })
+}
+func (t ImmutableTimeData) AddHour(h int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddHour(h) })
+}
+func (t ImmutableTimeData) AddMinute(i int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddMinute(i) })
+}
+func (t ImmutableTimeData) AddSecond(s int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddSecond(s) })
+}
+func (t ImmutableTimeData) AddMillisecond(ms int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddMillisecond(ms) })
+}
+func (t ImmutableTimeData) AddMicrosecond(us int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddMicrosecond(us) })
+}
+func (t ImmutableTimeData) AddNanosecond(ns int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddNanosecond(ns) })
+}
+
+func (t ImmutableTimeData) SubYear(y int) ImmutableTimeData {
+	return t.AddYear(-y)
+}
+func (t ImmutableTimeData) SubMonth(m int) ImmutableTimeData {
+	return t.AddMonth(-m)
+}
+func (t ImmutableTimeData) SubQuarter(q int) ImmutableTimeData {
+	return t.AddQuarter(-q)
+}
+func (t ImmutableTimeData) SubDay(d int) ImmutableTimeData {
+	return t.AddDay(-d)
+}
+func (t ImmutableTimeData) SubHour(h int) ImmutableTimeData {
+	return t.AddHour(-h)
+}
+func (t ImmutableTimeData) SubMinute(i int) ImmutableTimeData {
+	return t.AddMinute(-i)
+}
+func (t ImmutableTimeData) SubSecond(s int) ImmutableTimeData {
+	return t.AddSecond(-s)
+}
+func (t ImmutableTimeData) SubMillisecond(ms int) ImmutableTimeData {
+	return t.AddNanosecond(-ms * 1e6)
+}
+func (t ImmutableTimeData) SubMicrosecond(us int) ImmutableTimeData {
+	return t.AddNanosecond(-us * 1e3)
+}
+func (t ImmutableTimeData) SubNanosecond(ns int) ImmutableTimeData {
+	return t.AddNanosecond(-ns)
+}
+
+// same as TimeData.AddAbsolute
+func (t ImmutableTimeData) AddAbsolute(d time.Duration) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.AddAbsolute(d) })
+}
+
+// same as TimeData.AddWallClock (returns the receiver as it is with an error)
+func (t ImmutableTimeData) AddWallClock(d time.Duration, policy int) (ImmutableTimeData, error) {
+	var err error
+	res := t.with(func(data *TimeData) { err = data.AddWallClock(d, policy) })
+	if err != nil {
+		return t, err
+	}
+	return res, nil
+}
+
+// ============================================================
+// Start / End
+// ============================================================
+
+// same as TimeData.StartOf (returns the receiver as it is with an error)
+func (t ImmutableTimeData) StartOf(unit Unit) (ImmutableTimeData, error) {
+	var err error
+	res := t.with(func(data *TimeData) { err = data.StartOf(unit) })
+	if err != nil {
+		return t, err
+	}
+	return res, nil
+}
+
+// same as TimeData.EndOf (returns the receiver as it is with an error)
+func (t ImmutableTimeData) EndOf(unit Unit) (ImmutableTimeData, error) {
+	var err error
+	res := t.with(func(data *TimeData) { err = data.EndOf(unit) })
+	if err != nil {
+		return t, err
+	}
+	return res, nil
+}
+
+// same as TimeData.SetWeekStart
+func (t ImmutableTimeData) SetWeekStart(w time.Weekday) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetWeekStart(w) })
+}
+
+// same as TimeData.SetFiscalCalendar
+func (t ImmutableTimeData) SetFiscalCalendar(cal *FiscalCalendar) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetFiscalCalendar(cal) })
+}
+
+// same as TimeData.SetDisambiguation
+func (t ImmutableTimeData) SetDisambiguation(policy int) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.SetDisambiguation(policy) })
+}
+
+func (t ImmutableTimeData) TruncateTo(d time.Duration) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.TruncateTo(d) })
+}
+func (t ImmutableTimeData) RoundTo(d time.Duration) ImmutableTimeData {
+	return t.with(func(data *TimeData) { data.RoundTo(d) })
+}
+
+// ============================================================
+// Comparison
+// ============================================================
+
+func (t ImmutableTimeData) Before(u ImmutableTimeData) bool {
+	return t.get().Before(u.get())
+}
+func (t ImmutableTimeData) After(u ImmutableTimeData) bool {
+	return t.get().After(u.get())
+}
+func (t ImmutableTimeData) Equal(u ImmutableTimeData) bool {
+	return t.get().Equal(u.get())
+}
+func (t ImmutableTimeData) Compare(u ImmutableTimeData) int {
+	return t.get().Compare(u.get())
+}
+
+// ============================================================
+// time / Format
+// ============================================================
+
+func (t ImmutableTimeData) Time() *time.Time {
+	return t.get().Time()
+}
+func (t ImmutableTimeData) Unix() int64 {
+	return t.get().Unix()
+}
+func (t ImmutableTimeData) UnixNano() int64 {
+	return t.get().UnixNano()
+}
+func (t ImmutableTimeData) Format(s string) string {
+	return t.get().Format(s)
+}
+func (t ImmutableTimeData) String() string {
+	return t.get().String()
+}
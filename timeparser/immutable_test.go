@@ -0,0 +1,150 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	data, _ := New("2021-12-29 18:24:00 +1 day")
+	c := data.Clone()
+	c.AddDay(1)
+	c.additions = append(c.additions, *newTimeAddition(1, "month"))
+
+	assert.Equal(t, "2021-12-30 18:24:00", data.Format("Y-m-d H:i:s"))
+	assert.Equal(t, "2021-12-31 18:24:00", c.Format("Y-m-d H:i:s"))
+	assert.Equal(t, len(data.additions)+1, len(c.additions))
+}
+
+func TestImmutable(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	base, err := NewImmutable("2021-12-29 18:24:30")
+	assert.Nil(t, err)
+
+	d, err := base.AddDay(3).StartOf(UNIT_MONTH)
+	assert.Nil(t, err)
+	d = d.In(tokyo)
+	assert.Equal(t, "2021-12-29 18:24:30", base.Format("Y-m-d H:i:s"))
+	assert.Equal(t, "2022-01-01 00:00:00 Asia/Tokyo", d.Format("Y-m-d H:i:s e"))
+
+	testcases := []struct {
+		res      ImmutableTimeData
+		expected string
+	}{
+		{base.SetYear(2020), "2020-12-29 18:24:30"},
+		{base.SetMonth(2), "2021-02-28 18:24:30"},
+		{base.SetDay(1).SetHour(0), "2021-12-01 00:24:30"},
+		{base.AddMonth(2), "2022-02-28 18:24:30"},
+		{base.SubQuarter(1), "2021-09-29 18:24:30"},
+		{base.AddHour(6).AddMinute(-24), "2021-12-30 00:00:30"},
+		{base.SubSecond(30), "2021-12-29 18:24:00"},
+		{base.TruncateTo(time.Hour), "2021-12-29 18:00:00"},
+		{base.RoundTo(time.Hour), "2021-12-29 18:00:00"},
+		{base.SetISOWeek(2020, 53, 7), "2021-01-03 18:24:30"},
+		{base.SetDayOfYear(1), "2021-01-01 18:24:30"},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.expected, tc.res.Format("Y-m-d H:i:s"))
+	}
+	assert.Equal(t, "2021-12-29 18:24:30", base.Format("Y-m-d H:i:s"))
+
+	// start / end
+	startcases := []struct {
+		res      ImmutableTimeData
+		unit     Unit
+		end      bool
+		expected string
+	}{
+		{base, UNIT_YEAR, true, "2021-12-31 23:59:59"},
+		{base, UNIT_ISOWEEK, false, "2021-12-27 00:00:00"},
+		{base, "hour", false, "2021-12-29 18:00:00"},
+		{base.SetWeekStart(time.Sunday), UNIT_WEEK, false, "2021-12-26 00:00:00"},
+		{base, UNIT_WEEK, false, "2021-12-27 00:00:00"},
+	}
+	for _, tc := range startcases {
+		res, err := tc.res.StartOf(tc.unit)
+		if tc.end {
+			res, err = tc.res.EndOf(tc.unit)
+		}
+		assert.Nil(t, err, tc.unit)
+		assert.Equal(t, tc.expected, res.Format("Y-m-d H:i:s"), tc.unit)
+	}
+	res, err := base.StartOf("unknown")
+	assert.NotNil(t, err)
+	assert.Equal(t, base, res)
+	res, err = base.EndOf(Unit("unknown"))
+	assert.NotNil(t, err)
+	assert.Equal(t, base, res)
+
+	// disambiguation
+	amb, err := NewImmutable("2021-11-07 01:30:00 America/New_York")
+	assert.Nil(t, err)
+	assert.Equal(t, "-05:00", FormatTime("P", amb.SetDisambiguation(DISAMBIGUATE_LATER).Time()))
+	assert.Equal(t, "-04:00", FormatTime("P", amb.Time()))
+
+	assert.Equal(t, time.Wednesday, base.GetWeekday())
+	assert.Equal(t, 52, base.GetISOWeek())
+
+	// DST
+	ny, _ := NewImmutable("2021-03-13 12:00:00 America/New_York")
+	assert.Equal(t, "2021-03-14 13:00:00", ny.AddAbsolute(24*time.Hour).Format("Y-m-d H:i:s"))
+	ny2, err := ny.AddWallClock(14*time.Hour+30*time.Minute, DISAMBIGUATE_REJECT)
+	assert.True(t, errors.Is(err, ErrNonexistentTime))
+	assert.Equal(t, ny, ny2)
+
+	// timezone
+	ny2 = ny.ConvertTo(tokyo)
+	assert.Equal(t, "2021-03-14 02:00:00 +09:00", ny2.Format("Y-m-d H:i:s P"))
+	assert.Equal(t, "2021-03-13 12:00:00 +09:00", ny.WithLocationKeepWallClock(tokyo).Format("Y-m-d H:i:s P"))
+	assert.True(t, ny.Equal(ny2))
+	tr, ok := ny.NextTransition()
+	assert.True(t, ok)
+	assert.Equal(t, "2021-03-14 03:00:00", FormatTime("Y-m-d H:i:s", &tr.Time))
+
+	// comparison
+	assert.True(t, base.Before(base.AddNanosecond(1)))
+	assert.True(t, base.After(base.SubNanosecond(1)))
+	assert.True(t, base.Equal(base.AddDay(1).SubDay(1)))
+	assert.Equal(t, 1, base.Compare(base.SubYear(1)))
+
+	// mutable copy
+	m := base.Mutable()
+	m.AddYear(1)
+	assert.Equal(t, 2022, m.GetYear())
+	assert.Equal(t, 2021, base.GetYear())
+
+	// immutable copy
+	m2, _ := New("2021-12-29 18:24:30")
+	im := m2.Immutable()
+	m2.AddYear(1)
+	assert.Equal(t, 2021, im.GetYear())
+}
+
+func TestImmutableConcurrent(t *testing.T) {
+	base, _ := NewImmutable("2021-12-29 18:24:30")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			d := base.AddDay(n).SetHour(n)
+			assert.Equal(t, n, d.GetHour())
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, "2021-12-29 18:24:30", base.Format("Y-m-d H:i:s"))
+}
+
+func ExampleImmutableTimeData() {
+	base, _ := NewImmutable("2021-12-29 18:24:30")
+	next, _ := base.AddDay(3).StartOf(UNIT_MONTH)
+
+	fmt.Println(base.Format("Y-m-d H:i:s")) // 2021-12-29 18:24:30
+	fmt.Println(next.Format("Y-m-d H:i:s")) // 2022-01-01 00:00:00
+}
@@ -0,0 +1,648 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==============================================================
+// Moment.js / Day.js tokens
+// ==============================================================
+//
+// Formats like "dddd, MMMM Do YYYY, h:mm:ss a" used by Moment.js and Day.js.
+// Localized formats (LT, LTS, L, LL, LLL, LLLL, l, ll, lll, llll) and
+// locale aware weeks ('w', 'gg', 'e') follow the "en" locale of Moment.js.
+
+// tokens of Moment.js (longer tokens have to be scanned first)
+var momentTokens = []string{
+	"SSSSSSSSS", "SSSSSSSS", "SSSSSSS", "SSSSSS", "SSSSS",
+	"YYYYYY", "NNNNN",
+	"MMMM", "DDDD", "DDDo", "dddd", "YYYY", "gggg", "GGGG", "NNNN", "SSSS",
+	"MMM", "DDD", "ddd", "NNN", "SSS",
+	"Mo", "MM", "Qo", "Do", "DD", "do", "dd", "wo", "ww", "Wo", "WW", "YY", "gg", "GG",
+	"hh", "HH", "kk", "mm", "ss", "SS", "zz", "ZZ", "NN",
+	"M", "Q", "D", "d", "e", "E", "w", "W", "Y", "a", "A",
+	"h", "H", "k", "m", "s", "S", "x", "X", "z", "Z", "N",
+}
+
+// localized formats of the "en" locale
+var momentLocalizedFormats = []string{
+	"LTS", "h:mm:ss A",
+	"LT", "h:mm A",
+	"LLLL", "dddd, MMMM D, YYYY h:mm A",
+	"LLL", "MMMM D, YYYY h:mm A",
+	"LL", "MMMM D, YYYY",
+	"L", "MM/DD/YYYY",
+	"llll", "ddd, MMM D, YYYY h:mm A",
+	"lll", "MMM D, YYYY h:mm A",
+	"ll", "MMM D, YYYY",
+	"l", "M/D/YYYY",
+}
+
+// a chunk of a Moment.js format
+type momentToken struct {
+	tok string // token (empty means literal text)
+	lit string // literal text
+}
+
+// split a Moment.js format into tokens and literal texts
+func tokenizeMoment(format string) []momentToken {
+	tokens := make([]momentToken, 0, len(format))
+	lit := make([]byte, 0, len(format))
+
+	f_len := len(format)
+	pos := 0
+loop:
+	for pos < f_len {
+		c := format[pos]
+
+		// [escaped]
+		if c == '[' {
+			if end := strings.IndexByte(format[pos:], ']'); end > 0 {
+				lit = append(lit, format[pos+1:pos+end]...)
+				pos += end + 1
+				continue
+			}
+		}
+		// \e
+		if c == '\\' && pos+1 < f_len {
+			lit = append(lit, format[pos+1])
+			pos += 2
+			continue
+		}
+
+		// localized formats
+		for i := 0; i < len(momentLocalizedFormats); i += 2 {
+			if strings.HasPrefix(format[pos:], momentLocalizedFormats[i]) {
+				if len(lit) > 0 {
+					tokens = append(tokens, momentToken{"", string(lit)})
+					lit = lit[:0]
+				}
+				tokens = append(tokens, tokenizeMoment(momentLocalizedFormats[i+1])...)
+				pos += len(momentLocalizedFormats[i])
+				continue loop
+			}
+		}
+
+		// tokens
+		for _, tok := range momentTokens {
+			if strings.HasPrefix(format[pos:], tok) {
+				if len(lit) > 0 {
+					tokens = append(tokens, momentToken{"", string(lit)})
+					lit = lit[:0]
+				}
+				tokens = append(tokens, momentToken{tok, ""})
+				pos += len(tok)
+				continue loop
+			}
+		}
+
+		lit = append(lit, c)
+		pos++
+	}
+	if len(lit) > 0 {
+		tokens = append(tokens, momentToken{"", string(lit)})
+	}
+	return tokens
+}
+
+// get the locale week ("en": weeks start on Sunday and the week 1 contains January 1st)
+func momentLocaleWeek(d *time.Time) (int, int) {
+	y := d.Year()
+	day := time.Date(y, d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+
+	next_start := momentLocaleWeekStart(y + 1)
+	if !day.Before(next_start) {
+		return y + 1, 1
+	}
+	start := momentLocaleWeekStart(y)
+	return y, int(day.Sub(start).Hours()/24)/7 + 1
+}
+
+// get the first day of the locale week 1
+func momentLocaleWeekStart(y int) time.Time {
+	jan1 := time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return jan1.AddDate(0, 0, -int(jan1.Weekday()))
+}
+
+// ==============================================================
+// format
+// ==============================================================
+
+func momentFormatToken(dst []byte, tok string, d *time.Time) []byte {
+	switch tok {
+	// Month
+	case "M":
+		return appendInt(dst, int(d.Month()), 1)
+	case "Mo":
+		return append(appendInt(dst, int(d.Month()), 1), ordinalSuffix(int(d.Month()))...)
+	case "MM":
+		return appendInt(dst, int(d.Month()), 2)
+	case "MMM":
+		return append(dst, d.Month().String()[0:3]...)
+	case "MMMM":
+		return append(dst, d.Month().String()...)
+
+	// Quarter
+	case "Q", "Qo":
+		q := (int(d.Month())-1)/3 + 1
+		dst = appendInt(dst, q, 1)
+		if tok == "Qo" {
+			dst = append(dst, ordinalSuffix(q)...)
+		}
+		return dst
+
+	// Day of Month
+	case "D":
+		return appendInt(dst, d.Day(), 1)
+	case "Do":
+		return append(appendInt(dst, d.Day(), 1), ordinalSuffix(d.Day())...)
+	case "DD":
+		return appendInt(dst, d.Day(), 2)
+
+	// Day of Year
+	case "DDD":
+		return appendInt(dst, d.YearDay(), 1)
+	case "DDDo":
+		return append(appendInt(dst, d.YearDay(), 1), ordinalSuffix(d.YearDay())...)
+	case "DDDD":
+		return appendInt(dst, d.YearDay(), 3)
+
+	// Day of Week
+	case "d", "e":
+		return appendInt(dst, int(d.Weekday()), 1)
+	case "do":
+		return append(appendInt(dst, int(d.Weekday()), 1), ordinalSuffix(int(d.Weekday()))...)
+	case "dd":
+		return append(dst, d.Weekday().String()[0:2]...)
+	case "ddd":
+		return append(dst, d.Weekday().String()[0:3]...)
+	case "dddd":
+		return append(dst, d.Weekday().String()...)
+	case "E":
+		return appendInt(dst, (int(d.Weekday())+6)%7+1, 1)
+
+	// Week of Year
+	case "w", "wo", "ww":
+		_, w := momentLocaleWeek(d)
+		if tok == "ww" {
+			return appendInt(dst, w, 2)
+		}
+		dst = appendInt(dst, w, 1)
+		if tok == "wo" {
+			dst = append(dst, ordinalSuffix(w)...)
+		}
+		return dst
+	case "W", "Wo", "WW":
+		_, w := d.ISOWeek()
+		if tok == "WW" {
+			return appendInt(dst, w, 2)
+		}
+		dst = appendInt(dst, w, 1)
+		if tok == "Wo" {
+			dst = append(dst, ordinalSuffix(w)...)
+		}
+		return dst
+
+	// Year
+	case "YY":
+		return appendInt(dst, (d.Year()%100+100)%100, 2)
+	case "YYYY":
+		return appendInt(dst, d.Year(), 4)
+	case "YYYYYY":
+		if d.Year() >= 0 {
+			dst = append(dst, '+')
+		}
+		return appendInt(dst, d.Year(), 6)
+	case "Y":
+		if d.Year() > 9999 {
+			dst = append(dst, '+')
+		}
+		return appendInt(dst, d.Year(), 1)
+
+	// Week Year
+	case "gg", "gggg":
+		y, _ := momentLocaleWeek(d)
+		if tok == "gg" {
+			return appendInt(dst, (y%100+100)%100, 2)
+		}
+		return appendInt(dst, y, 4)
+	case "GG", "GGGG":
+		y, _ := d.ISOWeek()
+		if tok == "GG" {
+			return appendInt(dst, (y%100+100)%100, 2)
+		}
+		return appendInt(dst, y, 4)
+
+	// Era
+	case "N", "NN", "NNN", "NNNNN":
+		if d.Year() > 0 {
+			return append(dst, "AD"...)
+		}
+		return append(dst, "BC"...)
+	case "NNNN":
+		if d.Year() > 0 {
+			return append(dst, "Anno Domini"...)
+		}
+		return append(dst, "Before Christ"...)
+
+	// AM/PM
+	case "A":
+		if d.Hour() < 12 {
+			return append(dst, "AM"...)
+		}
+		return append(dst, "PM"...)
+	case "a":
+		if d.Hour() < 12 {
+			return append(dst, "am"...)
+		}
+		return append(dst, "pm"...)
+
+	// Hour
+	case "H", "HH":
+		return appendInt(dst, d.Hour(), len(tok))
+	case "h", "hh":
+		h := d.Hour() % 12
+		if h == 0 {
+			h = 12
+		}
+		return appendInt(dst, h, len(tok))
+	case "k", "kk":
+		h := d.Hour()
+		if h == 0 {
+			h = 24
+		}
+		return appendInt(dst, h, len(tok))
+
+	// Minute / Second
+	case "m", "mm":
+		return appendInt(dst, d.Minute(), len(tok))
+	case "s", "ss":
+		return appendInt(dst, d.Second(), len(tok))
+
+	// Timezone
+	case "z", "zz":
+		name_, offset_ := d.Zone()
+		if name_ == "" {
+			return appendISOOffset(dst, offset_, true, true, false)
+		}
+		return append(dst, name_...)
+	case "Z":
+		_, offset_ := d.Zone()
+		return appendISOOffset(dst, offset_, true, true, false)
+	case "ZZ":
+		_, offset_ := d.Zone()
+		return appendISOOffset(dst, offset_, false, true, false)
+
+	// Unix Timestamp
+	case "X":
+		return appendInt(dst, int(d.Unix()), 1)
+	case "x":
+		return appendInt(dst, int(d.UnixMilli()), 1)
+	}
+
+	// Fractional Seconds
+	if tok[0] == 'S' {
+		frac := appendInt(make([]byte, 0, 9), d.Nanosecond(), 9)
+		return append(dst, frac[0:len(tok)]...)
+	}
+	return append(dst, tok...)
+}
+
+// Format a time.Time variable to a string with Moment.js tokens
+func FormatMoment(format string, dt *time.Time) string {
+	if dt == nil {
+		d := time.Now()
+		dt = &d
+	}
+	if format == "" {
+		format = "YYYY-MM-DDTHH:mm:ssZ" // default format of Moment.js
+	}
+
+	tokens := tokenizeMoment(format)
+
+	dst := make([]byte, 0, len(format)+16)
+	for i := range tokens {
+		if tokens[i].tok == "" {
+			dst = append(dst, tokens[i].lit...)
+		} else {
+			dst = momentFormatToken(dst, tokens[i].tok, dt)
+		}
+	}
+	return string(dst)
+}
+
+// ==============================================================
+// parse
+// ==============================================================
+
+// parse a weekday name including its 2 letter abbreviation (Su, Mo, ...)
+func parseMomentWeekday(s *string, pos_s *int) (int, bool) {
+	if n, ok := parseWeekday(s, pos_s); ok {
+		return n, true
+	}
+	if len(*s)-*pos_s < 2 {
+		return -1, false
+	}
+	for _, w := range []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday} {
+		if cmpiStartWith((*s)[*pos_s:], w.String()[0:2]) {
+			*pos_s += 2
+			return int(w), true
+		}
+	}
+	return -1, false
+}
+
+// Convert a datetime string to a time.Time variable with Moment.js tokens
+//
+// Missing leading date fields are taken from the current date like Moment.js ("MM-DD" is in this year).
+// Trailing data is an error like the strict mode of Moment.js.
+func ParseMoment(format string, s string) (*time.Time, error) {
+	return ParseMomentWithBase(format, s, nil)
+}
+
+// same as ParseMoment but missing leading date fields are taken from base (nil means now)
+func ParseMomentWithBase(format string, s string, base *time.Time) (*time.Time, error) {
+	data, err := ParseMomentData(format, s, base)
+	if err != nil {
+		return nil, err
+	}
+	return data.resolve()
+}
+
+// Convert a datetime string to a TimeData variable with Moment.js tokens
+// (missing leading date fields are taken from base, nil means now)
+func ParseMomentData(format string, s string, base *time.Time) (*TimeData, error) {
+	format = strings.TrimSpace(format)
+	if format == "" {
+		return nil, errors.New("empty format")
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty data")
+	}
+
+	tokens := tokenizeMoment(format)
+	data := newTimeData()
+
+	s_len := len(s)
+	pos_s := 0
+
+	df := newDeferredFields()
+
+	for i := range tokens {
+		tok := tokens[i].tok
+
+		// literal
+		if tok == "" {
+			lit := tokens[i].lit
+			for j := 0; j < len(lit); j++ {
+				if isSpace(lit[j]) {
+					_ = skipSpaces(&s, &pos_s)
+					continue
+				}
+				if pos_s >= s_len || s[pos_s] != lit[j] {
+					return nil, errors.New(fmt.Sprintf("failed to parse format: [%s]", lit))
+				}
+				pos_s++
+			}
+			continue
+		}
+
+		if pos_s >= s_len {
+			return nil, errors.New(fmt.Sprintf("failed to parse format: %s", tok))
+		}
+
+		n := 0
+		ok := true
+		switch tok {
+		// Month
+		case "M", "MM":
+			if n, ok = parseInt(&s, &pos_s, 1, 2); ok {
+				ok = 1 <= n && n <= 12
+				data.setMonth(n)
+			}
+		case "Mo":
+			if n, ok = parseInt(&s, &pos_s, 1, 2); ok && parseSuffix(&s, &pos_s) {
+				ok = 1 <= n && n <= 12
+				data.setMonth(n)
+			} else {
+				ok = false
+			}
+		case "MMM", "MMMM":
+			if n, ok = parseMonth(&s, &pos_s); ok {
+				data.setMonth(n)
+			}
+
+		// Quarter
+		case "Q", "Qo":
+			if df.q, ok = parseInt(&s, &pos_s, 1, 1); ok && tok == "Qo" {
+				ok = parseSuffix(&s, &pos_s)
+			}
+			ok = ok && 1 <= df.q && df.q <= 4
+
+		// Day of Month
+		case "D", "DD", "Do":
+			if n, ok = parseInt(&s, &pos_s, 1, 2); ok && tok == "Do" {
+				ok = parseSuffix(&s, &pos_s)
+			}
+			if ok {
+				ok = 1 <= n && n <= 31
+				data.setDay(n)
+			}
+
+		// Day of Year
+		case "DDD", "DDDD", "DDDo":
+			if df.yday, ok = parseInt(&s, &pos_s, 1, 3); ok && tok == "DDDo" {
+				ok = parseSuffix(&s, &pos_s)
+			}
+			ok = ok && 1 <= df.yday && df.yday <= 366
+
+		// Day of Week
+		case "d", "e", "do":
+			if n, ok = parseInt(&s, &pos_s, 1, 1); ok && tok == "do" {
+				ok = parseSuffix(&s, &pos_s)
+			}
+			if ok {
+				ok = n <= 6
+				data.day = n
+				df.iso_d, df.loc_d = (n+6)%7+1, n
+			}
+		case "dd", "ddd", "dddd":
+			if n, ok = parseMomentWeekday(&s, &pos_s); ok {
+				data.day = n
+				df.iso_d, df.loc_d = (n+6)%7+1, n
+			}
+		case "E":
+			if df.iso_d, ok = parseInt(&s, &pos_s, 1, 1); ok {
+				ok = 1 <= df.iso_d && df.iso_d <= 7
+				data.day = df.iso_d % 7
+				df.loc_d = df.iso_d % 7
+			}
+
+		// Week of Year
+		case "w", "ww", "wo":
+			if df.loc_w, ok = parseInt(&s, &pos_s, 1, 2); ok && tok == "wo" {
+				ok = parseSuffix(&s, &pos_s)
+			}
+			ok = ok && 1 <= df.loc_w && df.loc_w <= 53
+		case "W", "WW", "Wo":
+			if df.iso_w, ok = parseInt(&s, &pos_s, 1, 2); ok && tok == "Wo" {
+				ok = parseSuffix(&s, &pos_s)
+			}
+			ok = ok && 1 <= df.iso_w && df.iso_w <= 53
+
+		// Year
+		case "YY", "gg", "GG":
+			if n, ok = parseInt(&s, &pos_s, 2, 2); ok {
+				// same as Moment.js (69-99 => 1969-1999)
+				if n > 68 {
+					n += 1900
+				} else {
+					n += 2000
+				}
+				switch tok {
+				case "gg":
+					df.loc_y = n
+				case "GG":
+					df.iso_y = n
+				default:
+					data.setYear(n)
+				}
+			}
+		case "YYYY", "gggg", "GGGG":
+			if n, ok = parseInt(&s, &pos_s, 1, 4); ok {
+				switch tok {
+				case "gggg":
+					df.loc_y = n
+				case "GGGG":
+					df.iso_y = n
+				default:
+					data.setYear(n)
+				}
+			}
+		case "YYYYYY", "Y":
+			sign_ := 1
+			if s[pos_s] == '+' {
+				pos_s++
+			} else if s[pos_s] == '-' {
+				sign_ = -1
+				pos_s++
+			}
+			if n, ok = parseInt(&s, &pos_s, 1, 9); ok {
+				data.setYear(n * sign_)
+			}
+
+		// Era
+		case "N", "NN", "NNN", "NNNN", "NNNNN":
+			var idx int
+			if idx, ok = parseICUWords(&s, &pos_s, []string{"anno domini", "before christ", "ad", "bc"}); ok {
+				df.bc = idx%2 == 1
+			}
+
+		// AM/PM
+		case "A", "a":
+			df.ap, ok = parseAMPM(&s, &pos_s)
+
+		// Hour
+		case "H", "HH", "h", "hh", "k", "kk":
+			if n, ok = parseInt(&s, &pos_s, 1, 2); ok {
+				switch tok[0] {
+				case 'H':
+					ok = n <= 23
+				case 'h':
+					ok = 1 <= n && n <= 12
+				case 'k':
+					ok = 1 <= n && n <= 24
+					n %= 24
+				}
+				data.setHour(n)
+			}
+
+		// Minute / Second
+		case "m", "mm":
+			if n, ok = parseInt(&s, &pos_s, 1, 2); ok {
+				ok = n <= 59
+				data.setMinute(n)
+			}
+		case "s", "ss":
+			if n, ok = parseInt(&s, &pos_s, 1, 2); ok {
+				ok = n <= 59
+				data.setSecond(n)
+			}
+
+		// Timezone
+		case "Z", "ZZ":
+			zone_s := pos_s
+			if n, ok = parseICUOffset(&s, &pos_s, true); ok {
+				data.setFixedZone(n)
+				data.zone = s[zone_s:pos_s]
+			}
+		case "z", "zz":
+			zone_s := pos_s
+			loc, ok_, err_ := parseLocation(&s, &pos_s)
+			if err_ != nil {
+				return nil, err_
+			}
+			if ok = ok_; ok {
+				data.setLocation(loc)
+				data.zone = s[zone_s:pos_s]
+			}
+
+		// Unix Timestamp
+		case "X", "x":
+			sign_ := 1
+			if s[pos_s] == '-' {
+				sign_ = -1
+				pos_s++
+			}
+			num_s := pos_s
+			if n, ok = parseInt(&s, &pos_s, 1, 19); ok {
+				// out of the range of int64
+				_, err_ := strconv.ParseInt(s[num_s:pos_s], 10, 64)
+				ok = err_ == nil && (pos_s >= s_len || !isNumeric(s[pos_s]))
+			}
+			if ok {
+				ns_ := 0
+				if tok == "x" {
+					ns_ = (n % 1000) * 1e6
+					n /= 1000
+				} else if pos_s < s_len && s[pos_s] == '.' {
+					pos_s++
+					var f float64
+					if f, ok = parseDecimal(&s, &pos_s, 1, 9); ok {
+						ns_ = int(f*1e9 + 0.5)
+					}
+				}
+				_t := time.Unix(int64(n*sign_), int64(ns_*sign_)) // .In()
+				data.setFromTime(&_t)
+			}
+
+		// Fractional Seconds
+		default:
+			max_ := len(tok)
+			if i+1 >= len(tokens) || tokens[i+1].tok == "" {
+				max_ = 9
+			}
+			var f float64
+			if f, ok = parseDecimal(&s, &pos_s, 1, max_); ok {
+				data.setNanosecond(int(f*1e9 + 0.5))
+			}
+		}
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("failed to parse format: %s", tok))
+		}
+	}
+
+	if pos_s < s_len {
+		return nil, errors.New(fmt.Sprintf("failed to parse format: trailing data '%s'", s[pos_s:]))
+	}
+
+	if err := df.apply(data, base); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,197 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFormatMoment(t *testing.T) {
+	utc, err := time.LoadLocation("UTC")
+	assert.Nil(t, err)
+
+	tm := time.Date(2021, time.December, 29, 18, 24, 36, 123456789, utc)
+
+	testcases := map[string]string{
+		// general format
+		"dddd, MMMM Do YYYY, h:mm:ss a": "Wednesday, December 29th 2021, 6:24:36 pm",
+		"YYYY-MM-DDTHH:mm:ss.SSSZ":      "2021-12-29T18:24:36.123+00:00",
+		"[Today is] dddd":               "Today is Wednesday",
+		"":                              "2021-12-29T18:24:36+00:00",
+
+		// localized formats
+		"LT":   "6:24 PM",
+		"LTS":  "6:24:36 PM",
+		"L":    "12/29/2021",
+		"LL":   "December 29, 2021",
+		"LLL":  "December 29, 2021 6:24 PM",
+		"LLLL": "Wednesday, December 29, 2021 6:24 PM",
+		"l":    "12/29/2021",
+		"ll":   "Dec 29, 2021",
+		"lll":  "Dec 29, 2021 6:24 PM",
+		"llll": "Wed, Dec 29, 2021 6:24 PM",
+
+		// Each token
+		"M Mo MM MMM MMMM":      "12 12th 12 Dec December",
+		"Q Qo":                  "4 4th",
+		"D Do DD DDD DDDo DDDD": "29 29th 29 363 363rd 363",
+		"d do dd ddd dddd e E":  "3 3rd We Wed Wednesday 3 3",
+		"w wo ww W Wo WW":       "1 1st 01 52 52nd 52",
+		"YY YYYY YYYYYY Y":      "21 2021 +002021 2021",
+		"gg gggg GG GGGG":       "22 2022 21 2021",
+		"N NNNN":                "AD Anno Domini",
+		"A a H HH h hh k kk":    "PM pm 18 18 6 06 18 18",
+		"m mm s ss":             "24 24 36 36",
+		"S SS SSS SSSSSS":       "1 12 123 123456",
+		"z Z ZZ":                "UTC +00:00 +0000",
+		"X x":                   "1640802276 1640802276123",
+		"\\Y\\Y [YYYY] YYYY":    "YY YYYY 2021",
+	}
+	for format, expected := range testcases {
+		assert.Equal(t, expected, FormatMoment(format, &tm), format)
+	}
+}
+
+func TestParseMoment(t *testing.T) {
+	expected := time.Date(2021, time.December, 29, 18, 24, 36, 0, time.Local)
+	testcases := map[string]string{
+		"dddd, MMMM Do YYYY, h:mm:ss a": "Wednesday, December 29th 2021, 6:24:36 pm",
+		"YYYYMMDDHHmmss":                "20211229182436",
+		"DD.MM.YY HH:mm:ss":             "29.12.21 18:24:36",
+		"GGGG-[W]WW-E HH:mm:ss":         "2021-W52-3 18:24:36",
+		"gggg [w]w d HH:mm:ss":          "2022 w1 3 18:24:36",
+		"DDDD YYYY k:m:s":               "363 2021 18:24:36",
+		"LLL:ss":                        "December 29, 2021 6:24 PM:36",
+		"[at] LTS [on] L":               "at 6:24:36 PM on 12/29/2021",
+	}
+	for format, s := range testcases {
+		tm, err := ParseMoment(format, s)
+
+		assert.Nil(t, err, format)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, expected.Year(), tm.Year(), format)
+		assert.Equal(t, expected.Month(), tm.Month(), format)
+		assert.Equal(t, expected.Day(), tm.Day(), format)
+		assert.Equal(t, expected.Hour(), tm.Hour(), format)
+		assert.Equal(t, expected.Minute(), tm.Minute(), format)
+		assert.Equal(t, expected.Second(), tm.Second(), format)
+		assert.Equal(t, expected.Location(), tm.Location(), format)
+	}
+
+	// quarters and fractions
+	tm, err := ParseMoment("Qo [quarter] YYYY", "3rd quarter 2021")
+	assert.Nil(t, err)
+	assert.Equal(t, time.July, tm.Month())
+
+	tm, err = ParseMoment("YYYY-MM-DD HH:mm:ss.SSS", "2021-10-05 01:02:03.123")
+	assert.Nil(t, err)
+	assert.Equal(t, time.October, tm.Month())
+	assert.Equal(t, 123000000, tm.Nanosecond())
+
+	// day of year in a leap year
+	tm, err = ParseMoment("DDD YYYY", "60 2020")
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-02-29", FormatTime("Y-m-d", tm))
+
+	tm, err = ParseMoment("DDDo [day of] YYYY", "366th day of 2020")
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-12-31", FormatTime("Y-m-d", tm))
+
+	_, err = ParseMoment("DDDD YYYY", "366 2021")
+	assert.NotNil(t, err)
+
+	// unix timestamps
+	tm, err = ParseMoment("X", "1640802276.5")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1640802276), tm.Unix())
+	assert.Equal(t, 500000000, tm.Nanosecond())
+
+	tm, err = ParseMoment("x", "1640802276123")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1640802276123), tm.UnixMilli())
+
+	// timezones are parsed in the same way as ParseFormat()
+	tm, err = ParseMoment("YYYY-MM-DDTHH:mm:ssZ", "2021-12-29T18:24:36+09:00")
+	assert.Nil(t, err)
+	tm2, err := ParseFormat("Y-m-d\\TH:i:sP", "2021-12-29T18:24:36+09:00")
+	assert.Nil(t, err)
+	assert.Equal(t, tm2.Unix(), tm.Unix())
+
+	// errors
+	errorcases := map[string]string{
+		"YYYY-MM-DD": "2021/12/29",
+		"MM":         "13",
+		"HH:mm":      "24:00",
+		"Do":         "29",
+		"dddd":       "Someday",
+
+		// trailing data
+		"MM/DD/YYYY":       "12/29/2021garbage",
+		"YYYY-MM-DDTHH:mm": "2021-12-29T18:24:36",
+
+		// out of the range of int64
+		"X": "99999999999999999999",
+		"x": "9999999999999999999",
+	}
+	for format, s := range errorcases {
+		_, err := ParseMoment(format, s)
+		assert.NotNil(t, err, format)
+	}
+	_, err = ParseMoment("YYYY-MM-DD", "2021-12-29garbage")
+	assert.NotNil(t, err)
+
+	tm, err = ParseMoment("x", "-1640771076000")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1640771076), tm.Unix())
+}
+
+func TestParseMomentWithBase(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.Nil(t, err)
+	base := time.Date(2000, time.September, 10, 11, 22, 33, 444, tokyo)
+
+	// missing leading date fields are taken from base
+	testcases := []struct {
+		format   string
+		s        string
+		expected string
+	}{
+		{"MM-DD", "12-29", "2000-12-29 00:00:00 +0900 JST"},
+		{"DD", "29", "2000-09-29 00:00:00 +0900 JST"},
+		{"YYYY", "2021", "2021-01-01 00:00:00 +0900 JST"},
+		{"HH:mm", "18:24", "2000-09-10 18:24:00 +0900 JST"},
+		{"DDD", "60", "2000-02-29 00:00:00 +0900 JST"},
+		{"Q", "3", "2000-07-01 00:00:00 +0900 JST"},
+	}
+	for _, tc := range testcases {
+		tm, err := ParseMomentWithBase(tc.format, tc.s, &base)
+		assert.Nil(t, err, tc.format)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc.expected, tm.String(), tc.format)
+	}
+
+	// nil means now
+	tm, err := ParseMoment("MM-DD", "12-29")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Now().Year(), tm.Year())
+	assert.Equal(t, 29, tm.Day())
+}
+
+func ExampleFormatMoment() {
+	tm := time.Now()
+	fmt.Println(FormatMoment("dddd, MMMM Do YYYY, h:mm:ss a", &tm)) // Wednesday, December 29th 2021, 6:24:00 pm
+	fmt.Println(FormatMoment("[Today is] LLLL", &tm))               // Today is Wednesday, December 29, 2021 6:24 PM
+}
+func ExampleParseMoment() {
+	// 2021-12-29 18:24:12 +0900 JST
+	tm, err := ParseMoment("dddd, MMMM Do YYYY, h:mm:ss a", "Wednesday, December 29th 2021, 6:24:12 pm")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(tm)
+}
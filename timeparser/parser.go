@@ -7,15 +7,19 @@ import (
 	//"fmt"
 )
 
-func preprocessScannedStr(s string) string {
+// normalize a string to be scanned
+// and return the positions of each byte in the original string
+func preprocessScannedStr(s string) (string, []int) {
 	s_len := len(s)
 	dst := make([]byte, 0, s_len)
+	pos_map := make([]int, 0, s_len+1)
 
 	head_flg := true // head of a word
 	pos := 0
 	for pos = 0; pos < s_len; pos++ {
 		// normalize spaces
 		if isSpace(s[pos]) || s[pos] == ',' {
+			pos_ := pos
 			pos++
 			for pos < s_len && (isSpace(s[pos]) || s[pos] == ',') {
 				pos++
@@ -24,6 +28,7 @@ func preprocessScannedStr(s string) string {
 				break
 			}
 			dst = append(dst, ' ')
+			pos_map = append(pos_map, pos_)
 			head_flg = true
 		}
 
@@ -47,11 +52,13 @@ func preprocessScannedStr(s string) string {
 		//}
 
 		dst = append(dst, c)
+		pos_map = append(pos_map, pos)
 
 		// head_flg for the next character
 		head_flg = isSpace(s[pos])
 	}
-	return string(dst)
+	pos_map = append(pos_map, s_len) // end of the string
+	return string(dst), pos_map
 }
 func scanWord(s string, pos_s int, word string, check_end bool) int {
 	s_len := len(s)
@@ -147,6 +154,21 @@ func scanMonth(s string, pos_s int) (m int, length int) {
 	}
 	return -1, -1
 }
+func scanMonthDay(s string, pos_s int) (d int, length int) {
+	// " \d{1,2}" after a month name (not followed by digits or a time)
+	s_len := len(s)
+	pos := pos_s
+	if pos >= s_len || s[pos] != ' ' {
+		return -1, -1
+	}
+	pos++
+
+	d_, ok := parseInt(&s, &pos, 1, 2)
+	if !ok || (pos < s_len && !isSpace(s[pos])) || d_ < 1 || 31 < d_ {
+		return -1, -1
+	}
+	return d_, (pos - pos_s)
+}
 func scanWeekday(s string, pos_s int) (w int, length int) {
 	w = -1
 	length = -1
@@ -290,17 +312,17 @@ func scanTime(s string, pos_s int) (h_ int, m_ int, s_ int, ns_ int, length int)
 	if h_, ok = parseInt(&s, &pos, 1, 2); !ok {
 		return -1, -1, -1, -1, -1
 	}
-	// :
-	if pos >= s_len || s[pos] != ':' {
-		return -1, -1, -1, -1, -1
-	}
-	pos++
-	// m
-	if m_, ok = parseInt(&s, &pos, 2, 2); !ok {
-		return -1, -1, -1, -1, -1
+	// : (may be omitted when followed by am/pm like "3pm")
+	hour_only := pos >= s_len || s[pos] != ':'
+	if !hour_only {
+		pos++
+		// m
+		if m_, ok = parseInt(&s, &pos, 2, 2); !ok {
+			return -1, -1, -1, -1, -1
+		}
 	}
 	// (:s)?
-	if pos < s_len && s[pos] == ':' {
+	if !hour_only && pos < s_len && s[pos] == ':' {
 		pos++
 		tmp_ := -1
 		if tmp_, ok = parseInt(&s, &pos, 2, 2); ok {
@@ -330,9 +352,14 @@ func scanTime(s string, pos_s int) (h_ int, m_ int, s_ int, ns_ int, length int)
 		return -1, -1, -1, -1, -1
 	}
 
-	// am / pm
-	if ap_, ok = parseAMPM(&s, &pos); ok {
-		if h_ < 1 && 12 < h_ {
+	// am / pm (not a prefix of a word like "America/New_York")
+	pos_ap := pos
+	if ap_, ok = parseAMPM(&s, &pos); ok && pos < s_len && !isSpace(s[pos]) && !isSeparator(s[pos]) {
+		pos = pos_ap
+		ok = false
+	}
+	if ok {
+		if h_ < 1 || 12 < h_ {
 			return -1, -1, -1, -1, -1
 		}
 		if ap_ == AM {
@@ -346,6 +373,8 @@ func scanTime(s string, pos_s int) (h_ int, m_ int, s_ int, ns_ int, length int)
 				h_ += 12
 			}
 		}
+	} else if hour_only {
+		return -1, -1, -1, -1, -1
 	}
 	return h_, m_, s_, ns_, (pos - pos_s)
 }
@@ -377,12 +406,13 @@ func scanDmy(s string, pos_s int) (y int, m int, d int, length int) {
 		return -1, -1, -1, -1
 	}
 	pos += len_
+	pos_ := pos
 
 	_ = skipSpaces(&s, &pos)
 
-	// year
+	// year (optional)
 	if y, ok = parseInt(&s, &pos, 4, 4); !ok {
-		return -1, -1, -1, -1
+		return -1, m, d, (pos_ - pos_s)
 	}
 	return y, m, d, (pos - pos_s)
 }
@@ -459,16 +489,26 @@ func scanYmd(s string, pos_s int) (y int, m int, d int, length int) {
 	}
 	return y, m, d, (pos - pos_s)
 }
-func scanRelativePosition(s string, pos_s int) (n int, unit string, length int) {
-	// ([\+\-]?)\s*(\d+|a)\s*(year|month|day|hour|minute|second|week|millisecond|microsecond|msec|ms|µsec|µs|usec|sec|min|forth?night)s?(\s+ago)?\b
+func scanRelativePosition(s string, pos_s int) (n int, unit string, ago bool, length int) {
+	// (in\s+)?([\+\-]?)\s*(\d+|a)\s*(year|month|day|hour|minute|second|week|millisecond|microsecond|msec|ms|µsec|µs|usec|sec|min|forth?night)s?(\s+ago)?\b
 	n = -1
 	unit = ""
+	ago = false
 	length = -1
 
 	s_len := len(s)
 	pos := pos_s
 	ok := false
 
+	// in
+	if len_ := scanWord(s, pos, "in", true); len_ > 0 {
+		pos += len_
+		_ = skipSpaces(&s, &pos)
+		if pos >= s_len {
+			return -1, "", false, -1
+		}
+	}
+
 	// sign
 	sign_ := 1
 	if s[pos] == '+' {
@@ -478,13 +518,16 @@ func scanRelativePosition(s string, pos_s int) (n int, unit string, length int)
 		pos++
 	}
 	_ = skipSpaces(&s, &pos)
+	if pos >= s_len {
+		return -1, "", false, -1
+	}
 
 	// \d+ | a
 	if s[pos] == 'a' && pos+1 < s_len && isSpace(s[pos+1]) {
 		n = 1
 		pos++
 	} else if n, ok = parseInt(&s, &pos, 1, 29); !ok {
-		return -1, "", -1
+		return -1, "", false, -1
 	}
 	n = n * sign_
 
@@ -495,11 +538,11 @@ func scanRelativePosition(s string, pos_s int) (n int, unit string, length int)
 		"year", "month", "day", "hour", "minute", "second",
 		"week", "millisecond", "microsecond", "msec", "ms",
 		"µsec", "µs", "usec", "nanosecond", "nsec", "ns",
-		"sec", "min", "fortnight", "forthnight",
+		"sec", "min", "fortnight", "forthnight", "quarter",
 	}
 	s_ := scanWords(s, pos, units, false)
 	if s_ == nil {
-		return -1, "", -1
+		return -1, "", false, -1
 	}
 	unit = *s_
 	pos += len(unit)
@@ -515,13 +558,14 @@ func scanRelativePosition(s string, pos_s int) (n int, unit string, length int)
 		if len_ := scanWord(s, pos, "ago", true); len_ > 0 {
 			pos += len_
 			n = n * -1
+			ago = true
 		}
 	}
 
-	return n, unit, (pos - pos_s)
+	return n, unit, ago, (pos - pos_s)
 }
 func scanPosition(s string, pos_s int) (*timeAddition, int) {
-	// `^((first|last) day of )?(next|last) ((year|month|day)|` + _months + `|` + _weeks + `)
+	// `^((first|last) day of )?(next|last) ((fiscal year|year|quarter|month|day)|` + _months + `|` + _weeks + `)
 
 	pos := pos_s
 	len_ := 0
@@ -569,8 +613,8 @@ day_pos:
 	pos += len(*pos_flg_)
 	_ = skipSpaces(&s, &pos)
 
-	// year | month | day | $month_names | $weekday_names
-	if word_ = scanWords(s, pos, []string{"year", "month", "day"}, true); word_ != nil {
+	// fiscal year | year | quarter | month | day | $month_names | $weekday_names
+	if word_ = scanWords(s, pos, []string{"fiscal year", "year", "quarter", "month", "day"}, true); word_ != nil {
 		pos += len(*word_)
 	} else if m_, len_ = scanMonth(s, pos); len_ >= 0 {
 		pos += len_
@@ -596,6 +640,42 @@ day_pos:
 	}
 	return a, (pos - pos_s)
 }
+func scanQuarter(s string, pos_s int) (y int, q int, length int) {
+	// q[1-4]( \d{4})? | \d{4}[ -]?q[1-4]
+	s_len := len(s)
+	pos := pos_s
+	y = -1
+	ok := false
+
+	// leading year
+	if pos+4 < s_len && isNumeric(s[pos]) {
+		if y, ok = parseInt(&s, &pos, 4, 4); !ok {
+			return -1, -1, -1
+		}
+		if pos < s_len && (s[pos] == '-' || s[pos] == ' ') {
+			pos++
+		}
+	}
+
+	// q[1-4]
+	if pos+1 >= s_len || (s[pos] != 'q' && s[pos] != 'Q') || s[pos+1] < '1' || '4' < s[pos+1] {
+		return -1, -1, -1
+	}
+	q = int(s[pos+1] - '0')
+	pos += 2
+	if pos < s_len && isAlphanumeric(s[pos]) {
+		return -1, -1, -1
+	}
+
+	// trailing year
+	if y < 0 && pos+5 <= s_len && s[pos] == ' ' && isNumeric(s[pos+1]) {
+		pos_ := pos + 1
+		if y_, ok_ := parseInt(&s, &pos_, 4, 4); ok_ && (pos_ >= s_len || !isAlphanumeric(s[pos_])) {
+			y, pos = y_, pos_
+		}
+	}
+	return y, q, (pos - pos_s)
+}
 func scanISOInterval(s string, pos_s int) ([]*timeAddition, int) {
 	// P1Y2M3DT4H5M6.7S
 	a := make([]*timeAddition, 0, 7)
@@ -706,29 +786,70 @@ func scanFormat(data *TimeData, s string, pos int) int {
 	if len_ := scanWord(s, pos, "now", true); len_ > 0 {
 		data.setNow()
 		pos += len_
+	} else if len_ := scanWord(s, pos, "just now", true); len_ > 0 {
+		// the base time (humanized differences less than HumanizeOptions.JustNow)
+		pos += len_
 	} else if len_ := scanWord(s, pos, "yesterday", true); len_ > 0 {
-		data.appendAddition(newTimeAdditionWithTime(-1, "day", 0, 0, 0, 0))
+		data.appendAddition(newTimeAddition(-1, "day"))
+		data.setTime(0, 0, 0, 0)
+		pos += len_
+	} else if len_ := scanWord(s, pos, "tomorrow", true); len_ > 0 {
+		data.appendAddition(newTimeAddition(1, "day"))
+		data.setTime(0, 0, 0, 0)
 		pos += len_
 	} else if len_ := scanWord(s, pos, "midnight", true); len_ > 0 {
-		data.appendAddition(newTimeAdditionWithTime(0, "day", 0, 0, 0, 0))
+		data.setTime(0, 0, 0, 0)
 		pos += len_
 	} else if len_ := scanWord(s, pos, "today", true); len_ > 0 {
-		data.appendAddition(newTimeAdditionWithTime(0, "day", 0, 0, 0, 0))
+		data.setTime(0, 0, 0, 0)
 		pos += len_
 	} else if len_ := scanWord(s, pos, "noon", true); len_ > 0 {
-		data.appendAddition(newTimeAdditionWithTime(0, "day", 0, 0, 0, 0))
+		data.setTime(12, 0, 0, 0)
+		pos += len_
+	} else if len_ := scanWord(s, pos, "at", true); len_ > 0 {
+		// "yesterday at 5pm"
 		pos += len_
 	} else if m_, len_ := scanMonth(s, pos); len_ >= 0 {
 		// month name
 		data.setMonth(m_)
 		pos += len_
-	} else if _, len_ := scanWeekday(s, pos); len_ >= 0 {
+		// Dec 1
+		if d_, len_ := scanMonthDay(s, pos); len_ > 0 {
+			data.setDay(d_)
+			pos += len_
+		}
+	} else if w_, len_ := scanWeekday(s, pos); len_ >= 0 {
 		// weekday name
-		//data.setWeekday(num_)
+		data.setWeekday(w_)
 		pos += len_
-	} else if n_, unit_, len_ := scanRelativePosition(s, pos); len_ >= 0 {
+	} else if fy_, q_, p_, len_ := scanFiscalYear(s, pos); len_ > 0 {
+		// FY2022 Q1 (the first day of the fiscal year, quarter or period)
+		p := 1
+		if q_ > 0 {
+			p = q_*3 - 2
+		} else if p_ > 0 {
+			p = p_
+		}
+		y_, m_ := getFiscalCalendar(data.cal).periodStart(fy_, p)
+		data.setYear(y_)
+		data.setMonth(m_)
+		data.setDay(1)
+		pos += len_
+	} else if y_, q_, len_ := scanQuarter(s, pos); len_ > 0 {
+		// Q3 2021 (the first day of the quarter)
+		if y_ >= 0 {
+			data.setYear(y_)
+		}
+		data.setMonth(q_*3 - 2)
+		data.setDay(1)
+		pos += len_
+	} else if n_, unit_, ago_, len_ := scanRelativePosition(s, pos); len_ >= 0 {
 		// relative format (1 year .. etc)
 		data.appendAddition(newTimeAddition(n_, unit_))
+		if ago_ {
+			// "1 year 2 months ago" negates "1 year" as well
+			data.negateAdditions()
+		}
 		pos += len_
 	} else if a_, len_ := scanISOInterval(s, pos); len_ >= 0 {
 		// iso8601 interval format (P1Y2M3DT1H2M3S)
@@ -742,7 +863,9 @@ func scanFormat(data *TimeData, s string, pos int) int {
 		pos += len_
 	} else if y_, m_, d_, len_ := scanDmy(s, pos); len_ > 0 {
 		// 10th January 2021
-		data.setYear(y_)
+		if y_ >= 0 {
+			data.setYear(y_)
+		}
 		data.setMonth(m_)
 		data.setDay(d_)
 		pos += len_
@@ -766,16 +889,13 @@ func scanFormat(data *TimeData, s string, pos int) int {
 	} else if s_, len_ := scanTimezoneOffset(s, pos); len_ > 0 {
 		// +00:00
 		// Z00:00
-		loc, err_ := time.LoadLocation("UTC")
-		if err_ != nil {
-			return -1
-		}
-		data.setLocation(loc)
-		data.setTimezoneOffset(s_)
+		data.setFixedZone(s_)
+		data.zone = s[pos : pos+len_]
 		pos += len_
 	} else if loc_, len_ := scanLocation(s, pos); len_ > 0 {
 		data.setLocation(loc_)
 		data.setTimezoneOffset(0)
+		data.zone = s[pos : pos+len_]
 		pos += len_
 	} else if y_, ok := parseInt(&s, &pos, 4, 4); ok { // pos has been already increased
 		// Year
@@ -788,11 +908,19 @@ func scanFormat(data *TimeData, s string, pos int) int {
 }
 
 // Convert string to a time.Time variable
-func parseTimeStr(format string, base *time.Time) (*TimeData, error) {
+// (warnings and errors are collected into r if it is not nil)
+func parseTimeStr(format string, base *time.Time, r *ParseReport, flags int) (*TimeData, int, error) {
+	return parseTimeStrWithCalendar(format, base, r, flags, nil)
+}
+
+// same as parseTimeStr but fiscal years follow cal (nil means the default calendar)
+func parseTimeStrWithCalendar(format string, base *time.Time, r *ParseReport, flags int, cal *FiscalCalendar) (*TimeData, int, error) {
 	//s := strings.TrimSpace(strings.ToLower(format))
+	offset := len(format) - len(strings.TrimLeft(format, " \t\r\n"))
 	s := strings.TrimSpace(format)
 	if s == "" {
-		return nil, errors.New("Failed to parse Time")
+		r.addError(0, "empty data")
+		return nil, -1, errors.New("Failed to parse Time")
 	}
 
 	// data
@@ -803,40 +931,164 @@ func parseTimeStr(format string, base *time.Time) (*TimeData, error) {
 		base = &t_
 	}
 	data.setFromTime(base)
+	data.flags = flags // only fields in the string are marked as specified
+	data.cal = cal
 
 	// convert datetime
-	s = preprocessScannedStr(s)
+	s, pos_map := preprocessScannedStr(s)
 
 	// parse string
 	s_len := len(s)
 	pos := 0
 	cnts := 0
+	timed := false // the time is set by a chunk other than keywords ("18:24:36" but not "noon")
 	for cnts < 15 && pos < s_len {
-		//pos_s := pos
+		pos_s := pos
+		if data.hasFlag(PREFIX) && pos_s > 0 {
+			// the rest is left at a token which is not a part of the date
+			if !scanPrefixFormat(data, s, pos, timed) {
+				break
+			}
+			hour := data.SpecifiedFields() & SET_HOUR
+			data.flags &^= SET_HOUR
+			pos = scanFormat(data, s, pos)
+			timed = timed || data.hasFlag(SET_HOUR) && !isKeywordChunk(s, pos_s)
+			data.flags |= hour
+			cnts++
+			continue
+		}
 		pos = scanFormat(data, s, pos)
 		if pos < 0 {
-			//fmt.Println(pos_s, s)
-			return nil, errors.New("Failed to parse Time")
+			r.addError(offset+pos_map[pos_s], "unexpected data")
+			return nil, -1, errors.New("Failed to parse Time")
 		}
+		timed = timed || data.hasFlag(SET_HOUR) && !isKeywordChunk(s, pos_s)
 		cnts++
 	}
+	if pos < s_len && !data.hasFlag(PREFIX) {
+		r.addWarning(offset+pos_map[pos], "trailing data")
+	}
+
+	data.checkFields(r, offset+pos_map[pos])
+	if data.hasFlag(STRICT) {
+		if err := data.checkRange(); err != nil {
+			r.addError(offset+pos_map[pos], err.Error())
+			return nil, -1, err
+		}
+	}
+
+	// the base is the later one of ambiguous wall clocks (01:30 EST after 01:30 EDT)
+	// only if no fields are specified
+	if data.SpecifiedFields()&(SET_YEAR|SET_MONTH|SET_DAY|SET_HOUR|SET_MINUTE|SET_SECOND|SET_NANOSECOND|SET_TIMEZONE_OFFSET|SET_TIMEZONE_LOCATION) != 0 {
+		data.fold = false
+	}
 
 	// additions
 	data.processAdditions()
+	fold := data.fold
+	data.normalize()
+	data.fold = fold
+
+	if data.hasFlag(PREFIX) {
+		return data, trimPrefixEnd(format, offset+pos_map[pos]), nil
+	}
+	return data, offset + pos_map[pos], nil
+}
 
-	return data, nil
+// check whether the format chunk at pos continues a parsed prefix
+//
+// the chunk must not re-set fields which are already specified ("May" after "2021-12-29")
+// and a word other than keywords must be followed by a delimiter or another chunk ("sat down", "a day to remember").
+// the time can be re-set only if it is set by keywords ("yesterday at noon" but not "18:24:36 5pm").
+// data is left untouched.
+func scanPrefixFormat(data *TimeData, s string, pos int, timed bool) bool {
+	tz := SET_TIMEZONE_OFFSET | SET_TIMEZONE_LOCATION
+	specified := data.SpecifiedFields() & (SET_YEAR | SET_MONTH | SET_DAY | SET_WEEKDAY)
+
+	d := data.Clone()
+	d.flags &^= specified | SET_HOUR
+	pos_e := scanFormat(d, s, pos)
+	if pos_e < 0 {
+		return false
+	}
+	if d.SpecifiedFields()&specified != 0 || timed && d.hasFlag(SET_HOUR) {
+		return false
+	}
+	if len(d.additions) > len(data.additions) && specified != 0 {
+		return false
+	}
+	for pos < pos_e && isSpace(s[pos]) {
+		pos++
+	}
+	if pos < pos_e && isAlpha(s[pos]) && isAlpha(s[pos_e-1]) && d.SpecifiedFields()&tz == data.SpecifiedFields()&tz &&
+		scanWords(s, pos, prefixKeywords, true) == nil {
+		for pos_e < len(s) && isSpace(s[pos_e]) {
+			pos_e++
+		}
+		// "sat down" but not "Dec 29"
+		if pos_e < len(s) && isAlpha(s[pos_e]) && scanFormat(d.Clone(), s, pos_e) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// check whether the chunk at pos starts with keywords like "noon"
+func isKeywordChunk(s string, pos int) bool {
+	for pos < len(s) && isSpace(s[pos]) {
+		pos++
+	}
+	return scanWords(s, pos, prefixKeywords, true) != nil
+}
+
+// words which are always a part of a parsed prefix
+var prefixKeywords = []string{"now", "just now", "yesterday", "tomorrow", "midnight", "today", "noon", "at"}
+
+// trim spaces and a dangling "at" from the end of a parsed prefix
+func trimPrefixEnd(s string, n int) int {
+	for n > 0 && isSpace(s[n-1]) {
+		n--
+	}
+	if n >= 2 && scanWord(s, n-2, "at", false) > 0 && (n == 2 || isSpace(s[n-3])) {
+		n -= 2
+		for n > 0 && isSpace(s[n-1]) {
+			n--
+		}
+	}
+	return n
 }
 
 // Convert string to a time.Time variable
 func ParseTimeStr(format string, base *time.Time) (*time.Time, error) {
-	data, err := parseTimeStr(format, base)
+	data, _, err := parseTimeStr(format, base, nil, 0)
 	if err != nil {
 		return nil, err
 	}
 
 	// result
-	res := data.Time()
+	return data.resolve()
+}
+
+// Convert string to a TimeData variable
+// and report warnings and errors like PHP's DateTime::getLastErrors()
+func ParseTimeStrWithReport(format string, base *time.Time) (*TimeData, *ParseReport, error) {
+	r := newParseReport()
+	data, _, err := parseTimeStr(format, base, r, 0)
+	return data, r, err
+}
+
+// same as ParseTimeStr but returns ErrOutOfRange for overflowing dates and times
+// (e.g. 30 February 2021) instead of normalizing them
+func ParseTimeStrStrict(format string, base *time.Time) (*time.Time, error) {
+	data, _, err := parseTimeStr(format, base, nil, STRICT)
+	if err != nil {
+		return nil, err
+	}
+	return data.resolve()
+}
 
-	// return &res, &data
-	return res, nil
+// Parse the leading part of s (the longest valid date prefix)
+// and return the number of bytes consumed (the rest of s is left)
+func ParseTimeStrPrefix(s string) (*TimeData, int, error) {
+	return parseTimeStr(s, nil, nil, PREFIX)
 }
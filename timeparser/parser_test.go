@@ -10,6 +10,8 @@ import (
 func TestParseTimeStr(t *testing.T) {
 	utc, err := time.LoadLocation("UTC")
 	assert.Nil(t, err)
+	newyork, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
 
 	base := time.Date(2000, time.September, 10, 0, 0, 0, 0, time.Local)
 
@@ -21,13 +23,31 @@ func TestParseTimeStr(t *testing.T) {
 		"2000-09-10 12:34:56": time.Date(2000, time.September, 10, 12, 34, 56, 0, time.Local),
 
 		"Wed, 29 Dec 2021 18:24:00 +0000":          time.Date(2021, time.December, 29, 18, 24, 0, 0, utc),
-		"Wed, 29 Dec 2021 18:24:00 +0900":          time.Date(2021, time.December, 30, 3, 24, 0, 0, utc),
-		"Wed, 29 Dec 2021 18:24:00 +08:00":         time.Date(2021, time.December, 30, 2, 24, 0, 0, utc),
-		"2021-12-29T18:24:00+09:00":                time.Date(2021, time.December, 30, 3, 24, 0, 0, utc),
+		"Wed, 29 Dec 2021 18:24:00 +0900":          time.Date(2021, time.December, 29, 18, 24, 0, 0, time.FixedZone("", 9*3600)),
+		"Wed, 29 Dec 2021 18:24:00 +08:00":         time.Date(2021, time.December, 29, 18, 24, 0, 0, time.FixedZone("", 8*3600)),
+		"2021-12-29T18:24:00+09:00":                time.Date(2021, time.December, 29, 18, 24, 0, 0, time.FixedZone("", 9*3600)),
+		"2021-12-29 01:30:00 America/New_York":     time.Date(2021, time.December, 29, 1, 30, 0, 0, newyork),
 		"Wednesday 29th December 2021 06:24:00 PM": time.Date(2021, time.December, 29, 18, 24, 0, 0, time.Local),
+		"December 29 2021":                         time.Date(2021, time.December, 29, 0, 0, 0, 0, time.Local),
+		"Dec 29 15:00":                             time.Date(2000, time.December, 29, 15, 0, 0, 0, time.Local),
+		"29 Dec":                                   time.Date(2000, time.December, 29, 0, 0, 0, 0, time.Local),
+
+		// Quarter
+		"Q3 2021": time.Date(2021, time.July, 1, 0, 0, 0, 0, time.Local),
+		"2021-Q4": time.Date(2021, time.October, 1, 0, 0, 0, 0, time.Local),
+		"2021Q2":  time.Date(2021, time.April, 1, 0, 0, 0, 0, time.Local),
+		"Q1":      time.Date(2000, time.January, 1, 0, 0, 0, 0, time.Local),
+
+		"next quarter":              time.Date(2000, time.December, 10, 0, 0, 0, 0, time.Local),
+		"+2 quarters":               time.Date(2001, time.March, 10, 0, 0, 0, 0, time.Local),
+		"1 quarter ago":             time.Date(2000, time.June, 10, 0, 0, 0, 0, time.Local),
+		"first day of last quarter": time.Date(2000, time.April, 1, 0, 0, 0, 0, time.Local),
+		"last day of next quarter":  time.Date(2000, time.December, 31, 0, 0, 0, 0, time.Local),
+		"first day of next month":   time.Date(2000, time.October, 1, 0, 0, 0, 0, time.Local),
+		"last day of last month":    time.Date(2000, time.August, 31, 0, 0, 0, 0, time.Local),
 
 		"2021-12-29T18:24:00Z":      time.Date(2021, time.December, 29, 18, 24, 0, 0, utc),
-		"2021-12-29T18:24:00Z09:00": time.Date(2021, time.December, 30, 3, 24, 0, 0, utc),
+		"2021-12-29T18:24:00Z09:00": time.Date(2021, time.December, 29, 18, 24, 0, 0, time.FixedZone("", 9*3600)),
 
 		// American or European format (sometimes this may be Ambiguious)
 		"9/10/2000": time.Date(2000, time.September, 10, 0, 0, 0, 0, time.Local),
@@ -51,6 +71,15 @@ func TestParseTimeStr(t *testing.T) {
 		// Other relative format
 		"next Thursday": time.Date(2000, time.September, 14, 0, 0, 0, 0, time.Local),
 		"last Monday":   time.Date(2000, time.September, 4, 0, 0, 0, 0, time.Local),
+
+		// Humanized format
+		"in 2 hours":           time.Date(2000, time.September, 10, 2, 0, 0, 0, time.Local),
+		"1 year 2 months ago":  time.Date(1999, time.July, 10, 0, 0, 0, 0, time.Local),
+		"yesterday at 5pm":     time.Date(2000, time.September, 9, 17, 0, 0, 0, time.Local),
+		"tomorrow noon":        time.Date(2000, time.September, 11, 12, 0, 0, 0, time.Local),
+		"14:00 today":          time.Date(2000, time.September, 10, 0, 0, 0, 0, time.Local),
+		"last Friday at 14:00": time.Date(2000, time.September, 8, 14, 0, 0, 0, time.Local),
+		"3 pm":                 time.Date(2000, time.September, 10, 15, 0, 0, 0, time.Local),
 	}
 	for format, expected := range testcases {
 		tm, err := ParseTimeStr(format, &base)
@@ -69,6 +98,85 @@ func TestParseTimeStr(t *testing.T) {
 		assert.Equal(t, expected.Location(), tm.Location())
 	}
 }
+func TestParseTimeStrPrefix(t *testing.T) {
+	testcases := map[string]int{
+		"2021-12-29 18:24:36 INFO server started":  19,
+		"2021-12-29T18:24:36+09:00 [warn] message": 25,
+		"Wed, 29 Dec 2021 18:24:00 +0900 - GET /":  31,
+		"  2021/12/29 10:00 at home":               18,
+		"2021-12-29 18:24:36":                      19,
+		// words which are not a part of the date
+		"2021-12-29 18:24:36 May the force be with you": 19,
+		"2021-12-29 18:24:36 a day to remember":         19,
+		"2021-12-29 18:24:36 sat down":                  19,
+		"2021-12-29 18:24:36 UTC message":               23,
+		"2021-12-29 at 18:24 tomorrow":                  19,
+		// messages which start with a digit, a month or a weekday
+		"2021-12-29 18:24:36 404 not found":        19,
+		"2021-12-29 18:24:36 10 users online":      19,
+		"2021-12-29 18:24:36 2022 budget approved": 19,
+		"2021-12-29 18:24:36 1.5 GB written":       19,
+		"2021-12-29 18:24:36 +10 retries":          19,
+		"2021-12-29 18:24:36 5pm meeting":          19,
+		"2021-12-29 18:24:36 March madness":        19,
+		"2021-12-29 18:24:36 Dec report uploaded":  19,
+		"2021-12-29 18:24:36 Monday backup done":   19,
+		"Dec 29 18:24:36 host sshd[123]: accepted": 15,
+		"Dec 29 18:24:36 10 users online":          15,
+	}
+	for s, expected := range testcases {
+		data, n, err := ParseTimeStrPrefix(s)
+		assert.Nil(t, err, s)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, expected, n, s)
+		assert.Equal(t, 29, data.GetDay(), s)
+	}
+
+	data, n, err := ParseTimeStrPrefix("2021-12-29 18:24:36 INFO server started")
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-12-29 18:24:36", data.Format("Y-m-d H:i:s"))
+	assert.Equal(t, " INFO server started", "2021-12-29 18:24:36 INFO server started"[n:])
+
+	// already parsed fields are not overwritten
+	data, n, err = ParseTimeStrPrefix("2021-12-29 18:24:36 May the force be with you")
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-12-29 18:24:36", data.Format("Y-m-d H:i:s"))
+	assert.Equal(t, " May the force be with you", "2021-12-29 18:24:36 May the force be with you"[n:])
+
+	data, _, err = ParseTimeStrPrefix("2021-12-29 18:24:36 a day to remember")
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-12-29 18:24:36", data.Format("Y-m-d H:i:s"))
+
+	data, n, err = ParseTimeStrPrefix("yesterday at noon we sat down")
+	assert.Nil(t, err)
+	assert.Equal(t, 17, n)
+	assert.Equal(t, 12, data.GetHour())
+
+	// the time is not re-set by a message
+	data, n, err = ParseTimeStrPrefix("2021-12-29 18:24:36 5pm meeting")
+	assert.Nil(t, err)
+	assert.Equal(t, 19, n)
+	assert.Equal(t, "2021-12-29 18:24:36", data.Format("Y-m-d H:i:s"))
+
+	data, _, err = ParseTimeStrPrefix("2021-12-29 18:24:36 sat down")
+	assert.Nil(t, err)
+	assert.False(t, data.hasFlag(SET_WEEKDAY))
+
+	// nothing can be parsed
+	_, _, err = ParseTimeStrPrefix("server started")
+	assert.NotNil(t, err)
+}
+func ExampleParseTimeStrPrefix() {
+	line := "2021-12-29 18:24:36 INFO server started"
+	data, n, err := ParseTimeStrPrefix(line)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(data.Format("Y-m-d H:i:s")) // 2021-12-29 18:24:36
+	fmt.Println(line[n:])                   //  INFO server started
+}
 func ExampleParseTimeStr() {
 	// Strtotime(format string) returns int64
 	// or -1 when an error has occurred.
@@ -0,0 +1,357 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ==============================================================
+// Range
+// ==============================================================
+
+// Range is a half-open span of time [Start, End)
+type Range struct {
+	Start time.Time // inclusive
+	End   time.Time // exclusive
+}
+
+// the last instant in the range
+func (r Range) EndInclusive() time.Time {
+	return r.End.Add(-time.Nanosecond)
+}
+
+// length of the range
+func (r Range) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// check if t is in the range
+func (r Range) Contains(t *time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// words between the start and the end (checked in this order)
+//
+// The end is exclusive after "until" and "till" ("Dec 1 until Dec 5" ends at the beginning of Dec 5)
+// and inclusive after the others ("Dec 1 to Dec 5" ends at the beginning of Dec 6).
+var rangeConnectors = []struct {
+	word      string
+	exclusive bool
+}{
+	{" to ", false},
+	{" until ", true},
+	{" till ", true},
+	{" through ", false},
+	{"–", false},
+	{"—", false},
+	{" - ", false},
+}
+
+// units of implicit spans
+var rangeUnits = []string{"week", "month", "quarter", "year"}
+
+// a side of a range or an implicit span
+type rangeSide struct {
+	start time.Time
+	end   time.Time // same as start if the side is a point
+	flags int       // specified fields
+}
+
+// start of the span of the unit which t belongs to (weeks start on ws)
+func rangeStartOf(t time.Time, unit string, ws time.Weekday) time.Time {
+	switch unit {
+	case "week":
+		w_ := floorMod(int(t.Weekday())-int(ws), 7)
+		return time.Date(t.Year(), t.Month(), t.Day()-w_, 0, 0, 0, 0, t.Location())
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case "quarter":
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, t.Location())
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// add n units to t
+func rangeAdd(t time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	case "quarter":
+		return t.AddDate(0, 3*n, 0)
+	case "year":
+		return t.AddDate(n, 0, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// the span of the unit which t belongs to
+func newRangeSpan(t time.Time, unit string, flags int, ws time.Weekday) *rangeSide {
+	start := rangeStartOf(t, unit, ws)
+	return &rangeSide{start, rangeAdd(start, unit, 1), flags}
+}
+
+// index of sub in s (case-insensitive)
+func indexRangeWord(s string, sub string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if cmpiStartWith(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// split a range into the start and the end
+// (exclusive is true if the end is exclusive)
+func splitRange(s string) (l string, r string, exclusive bool, ok bool) {
+	if cmpiStartWith(s, "between ") {
+		if i := indexRangeWord(s, " and "); i >= 0 {
+			return s[8:i], s[i+5:], false, true
+		}
+		return "", "", false, false
+	}
+	if cmpiStartWith(s, "from ") {
+		s = s[5:]
+	}
+	for _, c := range rangeConnectors {
+		if i := indexRangeWord(s, c.word); i >= 0 {
+			return s[:i], s[i+len(c.word):], c.exclusive, true
+		}
+	}
+	return "", "", false, false
+}
+
+// split a range by an unspaced dash ("9am-5pm", "Mon-Fri", "Dec 1-5", "Dec 28-Jan 3")
+// only if both sides are times or weekdays,
+// or the start is a month and a day and the end is another one or a day number (not "2021-12-29")
+func splitRangeDash(s string, base time.Time) (string, string, bool) {
+	if cmpiStartWith(s, "from ") {
+		s = s[5:]
+	}
+	for i := 1; i+1 < len(s); i++ {
+		if s[i] != '-' {
+			continue
+		}
+		l, r := s[:i], s[i+1:]
+		if isRangeDashSide(l, base) && isRangeDashSide(r, base) {
+			return l, r, true
+		}
+		if isRangeDashDate(l, base) && (isRangeDay(r) || isRangeDashDate(r, base)) {
+			return l, r, true
+		}
+	}
+	return "", "", false
+}
+
+// check if s is a time or a weekday without a date
+func isRangeDashSide(s string, base time.Time) bool {
+	data, _, err := parseTimeStr(strings.TrimSpace(s), &base, nil, 0)
+	if err != nil || data.HasYear() || data.HasMonth() || data.HasDay() || len(data.additions) > 0 {
+		return false
+	}
+	return data.HasTime() || data.hasFlag(SET_WEEKDAY)
+}
+
+// check if s is a month and a day without a time ("Dec 1")
+func isRangeDashDate(s string, base time.Time) bool {
+	data, _, err := parseTimeStr(strings.TrimSpace(s), &base, nil, 0)
+	if err != nil || data.HasTime() || len(data.additions) > 0 {
+		return false
+	}
+	return data.HasMonth() && data.HasDay()
+}
+
+// check if s is a bare day number ("5" of "Dec 1-5")
+func isRangeDay(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) < 1 || 2 < len(s) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isNumeric(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parse a side of a range
+// (fields which are not specified are inherited from `inherit`)
+func parseRangeSide(s string, base time.Time, inherit *rangeSide, ws time.Weekday) (*rangeSide, error) {
+	s = strings.TrimSpace(s)
+	s_len := len(s)
+	date_flags := SET_YEAR | SET_MONTH | SET_DAY
+
+	// today | yesterday | tomorrow
+	for i, w := range []string{"yesterday", "today", "tomorrow"} {
+		if scanWord(s, 0, w, true) == s_len {
+			return newRangeSpan(base.AddDate(0, 0, i-1), "day", date_flags, ws), nil
+		}
+	}
+
+	// this | last | next (week | month | quarter | year)
+	for i, w := range []string{"last", "this", "next"} {
+		len_ := scanWord(s, 0, w, true)
+		if len_ < 0 || len_ >= s_len || s[len_] != ' ' {
+			continue
+		}
+		if u := scanWords(s, len_+1, rangeUnits, true); u != nil && len_+1+len(*u) == s_len {
+			return newRangeSpan(rangeAdd(rangeStartOf(base, *u, ws), *u, i-1), *u, date_flags, ws), nil
+		}
+	}
+
+	// Q3 2021
+	if y_, q_, len_ := scanQuarter(s, 0); len_ == s_len {
+		flags := SET_MONTH | SET_DAY
+		switch {
+		case y_ >= 0:
+			flags |= SET_YEAR
+		case inherit != nil:
+			y_ = inherit.start.Year()
+		default:
+			y_ = base.Year()
+		}
+		return newRangeSpan(time.Date(y_, time.Month(q_*3-2), 1, 0, 0, 0, 0, base.Location()), "quarter", flags, ws), nil
+	}
+
+	// other formats
+	base_ := base
+	if inherit != nil && inherit.flags&(date_flags|SET_WEEKDAY) != 0 {
+		t := inherit.start
+		base_ = time.Date(t.Year(), t.Month(), t.Day(), base.Hour(), base.Minute(), base.Second(), base.Nanosecond(), t.Location())
+	}
+	data, _, err := parseTimeStr(s, &base_, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	t := *data.Time()
+	flags := data.SpecifiedFields()
+	if data.hasFlag(SET_WEEKDAY) && !data.HasDay() {
+		// "Monday" is the next Monday (or the same day) from the base
+		t = t.AddDate(0, 0, floorMod(data.day-int(t.Weekday()), 7))
+	}
+	switch {
+	case data.HasTime():
+		return &rangeSide{t, t, flags}, nil
+	case data.HasDay():
+		return newRangeSpan(t, "day", flags, ws), nil
+	case data.hasFlag(SET_WEEKDAY):
+		return newRangeSpan(t, "day", flags, ws), nil
+	case data.HasMonth():
+		return newRangeSpan(t, "month", flags, ws), nil
+	case data.HasYear():
+		return newRangeSpan(t, "year", flags, ws), nil
+	}
+	return &rangeSide{t, t, flags}, nil
+}
+
+// parse a bare day number ("5" of "Dec 1-5") with the month of the other side
+func parseRangeDay(s string, other *rangeSide, ws time.Weekday) (*rangeSide, error) {
+	s = strings.TrimSpace(s)
+	pos := 0
+	d_, ok := parseInt(&s, &pos, 1, 2)
+	if !ok || pos != len(s) || other.flags&SET_DAY == 0 || !checkDate(other.start.Year(), int(other.start.Month()), d_) {
+		return nil, errors.New(fmt.Sprintf("failed to parse range: %s", s))
+	}
+	t := other.start
+	return newRangeSpan(time.Date(t.Year(), t.Month(), d_, 0, 0, 0, 0, t.Location()), "day", SET_DAY, ws), nil
+}
+
+// parse "X to Y" (the end is the beginning of Y if exclusive is true)
+func parseRangeSides(l string, r string, exclusive bool, base time.Time, ws time.Weekday) (Range, error) {
+	ls, err := parseRangeSide(l, base, nil, ws)
+	var rs *rangeSide
+	if err == nil && isRangeDay(r) && ls.flags&SET_DAY != 0 {
+		// "Dec 1-5"
+		if rs, err = parseRangeDay(r, ls, ws); err != nil {
+			return Range{}, err
+		}
+	} else if err != nil {
+		// "1 - 5 Dec"
+		if rs, err = parseRangeSide(r, base, nil, ws); err != nil {
+			return Range{}, err
+		}
+		if ls, err = parseRangeDay(l, rs, ws); err != nil {
+			return Range{}, err
+		}
+	} else if rs, err = parseRangeSide(r, base, ls, ws); err != nil {
+		// "Dec 1 - 5"
+		if rs, err = parseRangeDay(r, ls, ws); err != nil {
+			return Range{}, err
+		}
+	}
+
+	// "Dec 1 to Dec 5 2020"
+	if ls.flags&SET_YEAR == 0 && rs.flags&SET_YEAR != 0 && ls.start.Year() != rs.start.Year() {
+		if ls_, err_ := parseRangeSide(l, base.AddDate(rs.start.Year()-base.Year(), 0, 0), nil, ws); err_ == nil {
+			ls = ls_
+		}
+	}
+
+	// "10pm to 2am"
+	if !rs.end.After(ls.start) && rs.flags&(SET_YEAR|SET_MONTH|SET_DAY) == 0 && rs.start.Equal(rs.end) {
+		rs.start, rs.end = rs.start.AddDate(0, 0, 1), rs.end.AddDate(0, 0, 1)
+	}
+	// "Dec 28 to Jan 3" (the year is not specified on either side)
+	if rs.end.Before(ls.start) && rs.flags&SET_MONTH != 0 && rs.start.Month() < ls.start.Month() {
+		switch {
+		case ls.flags&SET_YEAR == 0 && rs.flags&SET_YEAR != 0:
+			ls.start, ls.end = ls.start.AddDate(-1, 0, 0), ls.end.AddDate(-1, 0, 0)
+		case rs.flags&SET_YEAR == 0:
+			rs.start, rs.end = rs.start.AddDate(1, 0, 0), rs.end.AddDate(1, 0, 0)
+		}
+	}
+	end := rs.end
+	if exclusive {
+		end = rs.start
+	}
+	if end.Before(ls.start) {
+		return Range{}, errors.New(fmt.Sprintf("failed to parse range: the end is before the start (%s - %s)", l, r))
+	}
+	return Range{ls.start, end}, nil
+}
+
+// Parse a range of time ("from Dec 1 to Dec 5", "between 9am and 5pm", "last week")
+//
+// The start and the end are separated by "to", "until", "till", "through", "and" (after "between") or a dash
+// (an unspaced dash only between times or weekdays or after a month and a day: "9am-5pm", "Mon-Fri", "Dec 1-5").
+// Dates without times span the whole day, month, quarter or year,
+// so the end of "Dec 1 to Dec 5" is the beginning of Dec 6.
+// "until" and "till" exclude the end, so the end of "Dec 1 until Dec 5" is the beginning of Dec 5.
+// A side inherits unspecified fields from the other side ("Dec 1–5"),
+// the end moves to the next year if it is before the start ("Dec 28 to Jan 3")
+// and weekdays are the next ones from the base ("Monday to Friday").
+func ParseRange(s string, base *time.Time) (Range, error) {
+	return ParseRangeWithWeekStart(s, base, DEFAULT_WEEK_START)
+}
+
+// same as ParseRange but weeks ("this week", "last week") start on ws
+func ParseRangeWithWeekStart(s string, base *time.Time, ws time.Weekday) (Range, error) {
+	if base == nil {
+		t_ := time.Now()
+		base = &t_
+	}
+	s = strings.TrimSpace(s)
+
+	if l, r, exclusive, ok := splitRange(s); ok {
+		return parseRangeSides(l, r, exclusive, *base, ws)
+	}
+	if l, r, ok := splitRangeDash(s, *base); ok {
+		return parseRangeSides(l, r, false, *base, ws)
+	}
+
+	// implicit spans
+	side, err := parseRangeSide(s, *base, nil, ws)
+	if err != nil {
+		return Range{}, err
+	}
+	if !side.end.After(side.start) {
+		return Range{}, errors.New(fmt.Sprintf("failed to parse range: %s is not a span", s))
+	}
+	return Range{side.start, side.end}, nil
+}
@@ -0,0 +1,105 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+
+	testcases := map[string][2]time.Time{
+		// explicit ranges
+		"from Dec 1 to Dec 5":     {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 6, 0, 0, 0, 0, time.UTC)},
+		"2021-12-01 - 2021-12-05": {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 6, 0, 0, 0, 0, time.UTC)},
+		"between 9am and 5pm":     {time.Date(2021, 12, 29, 9, 0, 0, 0, time.UTC), time.Date(2021, 12, 29, 17, 0, 0, 0, time.UTC)},
+		"today through tomorrow":  {time.Date(2021, 12, 29, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)},
+		"today until tomorrow":    {time.Date(2021, 12, 29, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC)},
+		"Dec 1 until Dec 5":       {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 5, 0, 0, 0, 0, time.UTC)},
+		"Dec 1 till Dec 5":        {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 5, 0, 0, 0, 0, time.UTC)},
+		"Dec 1 through Dec 5":     {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 6, 0, 0, 0, 0, time.UTC)},
+		"Dec 29 at 3pm till 5pm":  {time.Date(2021, 12, 29, 15, 0, 0, 0, time.UTC), time.Date(2021, 12, 29, 17, 0, 0, 0, time.UTC)},
+		"10pm to 2am":             {time.Date(2021, 12, 29, 22, 0, 0, 0, time.UTC), time.Date(2021, 12, 30, 2, 0, 0, 0, time.UTC)},
+
+		// inherited fields
+		"Dec 1–5":             {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 6, 0, 0, 0, 0, time.UTC)},
+		"Dec 1-5":             {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 6, 0, 0, 0, 0, time.UTC)},
+		"1 - 5 Dec":           {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 6, 0, 0, 0, 0, time.UTC)},
+		"Dec 1 to Dec 5 2020": {time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 12, 6, 0, 0, 0, 0, time.UTC)},
+
+		// across the end of the year
+		"Dec 28 to Jan 3":      {time.Date(2021, 12, 28, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+		"Dec 28 to Jan 3 2022": {time.Date(2021, 12, 28, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+		"Dec 28 2021 - Jan 3":  {time.Date(2021, 12, 28, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+		"Dec 28-Jan 3":         {time.Date(2021, 12, 28, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+
+		// weekdays
+		"Monday to Friday":  {time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC)},
+		"Mon-Fri":           {time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC)},
+		"9am-5pm":           {time.Date(2021, 12, 29, 9, 0, 0, 0, time.UTC), time.Date(2021, 12, 29, 17, 0, 0, 0, time.UTC)},
+		"from 10:00-12:30":  {time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC), time.Date(2021, 12, 29, 12, 30, 0, 0, time.UTC)},
+		"Friday 9am to 5pm": {time.Date(2021, 12, 31, 9, 0, 0, 0, time.UTC), time.Date(2021, 12, 31, 17, 0, 0, 0, time.UTC)},
+		"Friday to Monday":  {time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC)},
+
+		// implicit spans
+		"yesterday":     {time.Date(2021, 12, 28, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 29, 0, 0, 0, 0, time.UTC)},
+		"last week":     {time.Date(2021, 12, 20, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 27, 0, 0, 0, 0, time.UTC)},
+		"this week":     {time.Date(2021, 12, 27, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)},
+		"next month":    {time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)},
+		"December 2021": {time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"Q3":            {time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)},
+		"2020-Q4":       {time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"2021":          {time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"2021-12-29":    {time.Date(2021, 12, 29, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	for s, expected := range testcases {
+		r, err := ParseRange(s, &base)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected[0].String(), r.Start.String(), s)
+		assert.Equal(t, expected[1].String(), r.End.String(), s)
+	}
+
+	// errors
+	for _, s := range []string{"now", "from Dec 5 to Dec 1", "from xxx to Dec 1", "Dec 1 - 32", "Dec 1-32", "Dec 5 until Dec 1"} {
+		_, err := ParseRange(s, &base)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestParseRangeWithWeekStart(t *testing.T) {
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+
+	r, err := ParseRangeWithWeekStart("this week", &base, time.Sunday)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2021, 12, 26, 0, 0, 0, 0, time.UTC).String(), r.Start.String())
+	assert.Equal(t, time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC).String(), r.End.String())
+
+	// ParseRange is not affected
+	r, err = ParseRange("this week", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2021, 12, 27, 0, 0, 0, 0, time.UTC).String(), r.Start.String())
+}
+
+func TestRange(t *testing.T) {
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+	r, err := ParseRange("Dec 1–5", &base)
+	assert.Nil(t, err)
+
+	assert.Equal(t, time.Date(2021, 12, 5, 23, 59, 59, 999999999, time.UTC), r.EndInclusive())
+	assert.Equal(t, 5*24*time.Hour, r.Duration())
+
+	t1 := time.Date(2021, 12, 5, 23, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, 12, 6, 0, 0, 0, 0, time.UTC)
+	assert.True(t, r.Contains(&r.Start))
+	assert.True(t, r.Contains(&t1))
+	assert.False(t, r.Contains(&t2))
+}
+
+func ExampleParseRange() {
+	base := time.Date(2021, 12, 29, 10, 0, 0, 0, time.UTC)
+	r, _ := ParseRange("from Dec 1 to Dec 5", &base)
+	fmt.Println(FormatTime("Y-m-d H:i", &r.Start), FormatTime("Y-m-d H:i", &r.End))
+	// 2021-12-01 00:00 2021-12-06 00:00
+}
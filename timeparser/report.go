@@ -0,0 +1,75 @@
+package timeparser
+
+import (
+	"fmt"
+	"time"
+)
+
+// ==============================================================
+// Parse Report
+// ==============================================================
+
+// ParseMessage is a warning or an error found while parsing
+type ParseMessage struct {
+	Pos     int    // byte offset in the parsed string
+	Message string // description
+}
+
+func (m ParseMessage) String() string {
+	return fmt.Sprintf("%s at %d", m.Message, m.Pos)
+}
+
+// ParseReport collects warnings and errors like PHP's DateTime::getLastErrors()
+type ParseReport struct {
+	Warnings []ParseMessage
+	Errors   []ParseMessage
+}
+
+func newParseReport() *ParseReport {
+	r := ParseReport{make([]ParseMessage, 0), make([]ParseMessage, 0)}
+	return &r
+}
+
+func (r *ParseReport) addWarning(pos int, message string) {
+	if r != nil {
+		r.Warnings = append(r.Warnings, ParseMessage{pos, message})
+	}
+}
+func (r *ParseReport) addError(pos int, message string) {
+	if r != nil {
+		r.Errors = append(r.Errors, ParseMessage{pos, message})
+	}
+}
+
+// number of warnings
+func (r *ParseReport) WarningCount() int {
+	return len(r.Warnings)
+}
+
+// number of errors
+func (r *ParseReport) ErrorCount() int {
+	return len(r.Errors)
+}
+
+// check parsed fields before they are normalized
+func (data *TimeData) checkFields(r *ParseReport, pos int) {
+	if r == nil {
+		return
+	}
+	if data.hasFlag(SET_MONTH) || data.hasFlag(SET_DAY) {
+		if !checkDate(data.y, data.m, data.d) {
+			r.addWarning(pos, "the parsed date was invalid")
+		}
+	}
+	if data.HasTime() {
+		if data.h < 0 || 23 < data.h || data.i < 0 || 59 < data.i || data.s < 0 || 59 < data.s {
+			r.addWarning(pos, "the parsed time was invalid")
+		}
+	}
+	if data.hasFlag(SET_WEEKDAY) && data.hasFlag(SET_DAY) {
+		w_ := int(time.Date(data.y, time.Month(data.m), data.d, 0, 0, 0, 0, time.UTC).Weekday())
+		if w_ != data.day {
+			r.addWarning(pos, fmt.Sprintf("the weekday does not match the date (%s)", time.Weekday(w_).String()))
+		}
+	}
+}
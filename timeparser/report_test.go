@@ -0,0 +1,90 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseFormatWithReport(t *testing.T) {
+	// no problems
+	data, r, err := ParseFormatWithReport("Y-m-d H:i:s", "2021-12-29 18:24:36")
+	assert.Nil(t, err)
+	assert.Equal(t, 29, data.GetDay())
+	assert.Equal(t, 0, r.WarningCount())
+	assert.Equal(t, 0, r.ErrorCount())
+
+	// overflowed dates are normalized with a warning
+	data, r, err = ParseFormatWithReport("Y-m-d", "2021-02-30")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, data.GetMonth())
+	assert.Equal(t, 2, data.GetDay())
+	assert.Equal(t, []ParseMessage{{10, "the parsed date was invalid"}}, r.Warnings)
+
+	// weekday mismatch
+	_, r, err = ParseFormatWithReport("D Y-m-d", "Mon 2021-12-29")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, r.WarningCount())
+	assert.Equal(t, "the weekday does not match the date (Wednesday) at 14", r.Warnings[0].String())
+
+	// trailing data
+	_, r, err = ParseFormatWithReport("Y-m-d", " 2021-12-29 18:24")
+	assert.Nil(t, err)
+	assert.Equal(t, []ParseMessage{{11, "trailing data"}}, r.Warnings)
+
+	// errors skipped by '+' are warnings
+	data, r, err = ParseFormatWithReport("Y-m-d+ H:i", "2021-12-29 xx")
+	assert.Nil(t, err)
+	assert.Equal(t, 29, data.GetDay())
+	assert.Equal(t, 1, r.WarningCount())
+	assert.Equal(t, 11, r.Warnings[0].Pos)
+	assert.Equal(t, 0, r.ErrorCount())
+
+	// errors
+	data, r, err = ParseFormatWithReport("Y-m-d H:i", "2021-12-29 xx")
+	assert.NotNil(t, err)
+	assert.Nil(t, data)
+	assert.Equal(t, 1, r.ErrorCount())
+	assert.Equal(t, 11, r.Errors[0].Pos)
+
+	_, r, err = ParseFormatWithReport("Y-m-d H:i", "2021-12-29")
+	assert.NotNil(t, err)
+	assert.Equal(t, []ParseMessage{{10, "data missing for format: H"}}, r.Errors)
+}
+
+func TestParseTimeStrWithReport(t *testing.T) {
+	base := time.Date(2000, time.September, 10, 0, 0, 0, 0, time.Local)
+
+	data, r, err := ParseTimeStrWithReport("2021-12-29 18:24:36", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, 29, data.GetDay())
+	assert.Equal(t, 0, r.WarningCount())
+	assert.Equal(t, 0, r.ErrorCount())
+
+	// overflowed dates
+	data, r, err = ParseTimeStrWithReport("30 February 2021", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, data.GetMonth())
+	assert.Equal(t, []ParseMessage{{16, "the parsed date was invalid"}}, r.Warnings)
+
+	// weekday mismatch
+	_, r, err = ParseTimeStrWithReport("Monday,  29th December 2021", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, r.WarningCount())
+
+	// errors (positions in the original string)
+	_, r, err = ParseTimeStrWithReport("  2021-12-29,   18:24:36 foo", &base)
+	assert.NotNil(t, err)
+	assert.Equal(t, []ParseMessage{{25, "unexpected data"}}, r.Errors)
+}
+
+func ExampleParseFormatWithReport() {
+	_, r, err := ParseFormatWithReport("Y-m-d", "2021-02-30")
+	if err != nil {
+		panic(err)
+	}
+	for _, w := range r.Warnings {
+		fmt.Println(w) // the parsed date was invalid at 10
+	}
+}
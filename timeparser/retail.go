@@ -0,0 +1,223 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ==============================================================
+// Retail Calendar
+// ==============================================================
+
+// Weeks of the periods in each quarter
+const (
+	RETAIL_445 = 0 // 4-4-5
+	RETAIL_454 = 1 // 4-5-4 (NRF)
+	RETAIL_544 = 2 // 5-4-4
+)
+
+// Rules of the last day of retail years
+const (
+	RETAIL_LAST_SATURDAY    = 0 // the last Saturday of EndMonth
+	RETAIL_NEAREST_SATURDAY = 1 // the Saturday nearest to the end of EndMonth (NRF)
+)
+
+var retailPatterns = [][3]int{{4, 4, 5}, {4, 5, 4}, {5, 4, 4}}
+
+// RetailCalendar is a 52-53 week calendar whose years end on a Saturday
+//
+// Each quarter has 13 weeks split into 3 periods by Pattern
+// and the 53rd week is added to the last period.
+// Years are named by the calendar year which contains most of their days
+// (e.g. the NRF year 2022 starts on 2022-01-30 and ends on 2023-01-28).
+type RetailCalendar struct {
+	Pattern  int // RETAIL_445 | RETAIL_454 | RETAIL_544
+	EndRule  int // RETAIL_LAST_SATURDAY | RETAIL_NEAREST_SATURDAY
+	EndMonth int // the month in which years end (1-12, 0 means January)
+}
+
+// NRF 4-5-4 calendar
+var NRFCalendar = RetailCalendar{RETAIL_454, RETAIL_NEAREST_SATURDAY, 1}
+
+// RetailDate is a date in a retail calendar
+type RetailDate struct {
+	Year       int // retail year
+	Quarter    int // 1-4
+	Period     int // 1-12
+	Week       int // week of the period (1-5)
+	WeekOfYear int // 1-53
+	Weekday    int // day of the week (1 = Sunday ... 7 = Saturday)
+}
+
+// the month in which years end (1-12)
+func (cal *RetailCalendar) endMonth() int {
+	if cal.EndMonth < 1 || 12 < cal.EndMonth {
+		return 1
+	}
+	return cal.EndMonth
+}
+
+// weeks of the period (without the 53rd week)
+func (cal *RetailCalendar) periodWeeks(p int) int {
+	pattern := cal.Pattern
+	if pattern < RETAIL_445 || RETAIL_544 < pattern {
+		pattern = RETAIL_445
+	}
+	return retailPatterns[pattern][(p-1)%3]
+}
+
+// the last day of the retail year (00:00 UTC)
+func (cal *RetailCalendar) yearEnd(y int) time.Time {
+	m := cal.endMonth()
+	if m < 7 {
+		y++
+	}
+	last := time.Date(y, time.Month(m), getLastDay(y, m), 0, 0, 0, 0, time.UTC)
+	n := (int(time.Saturday) - int(last.Weekday()) + 7) % 7 // days to the next Saturday
+	if cal.EndRule == RETAIL_NEAREST_SATURDAY && n <= 3 {
+		return last.AddDate(0, 0, n)
+	}
+	return last.AddDate(0, 0, -((7 - n) % 7))
+}
+
+// the first day of the retail year (00:00 UTC)
+func (cal *RetailCalendar) yearStart(y int) time.Time {
+	return cal.yearEnd(y-1).AddDate(0, 0, 1)
+}
+
+// number of weeks in the retail year (52 or 53)
+func (cal *RetailCalendar) Weeks(y int) int {
+	return int(cal.yearEnd(y).Sub(cal.yearStart(y)).Hours()/24)/7 + 1
+}
+
+// first week (0-based) of the period
+func (cal *RetailCalendar) periodOffset(p int) int {
+	w := 0
+	for i := 1; i < p; i++ {
+		w += cal.periodWeeks(i)
+	}
+	return w
+}
+
+// Map the date to the retail calendar
+func (cal *RetailCalendar) Date(data *TimeData) RetailDate {
+	d := time.Date(data.y, time.Month(data.m), data.d, 0, 0, 0, 0, time.UTC)
+
+	y := d.Year()
+	if cal.endMonth() < 7 {
+		y--
+	}
+	for d.Before(cal.yearStart(y)) {
+		y--
+	}
+	for d.After(cal.yearEnd(y)) {
+		y++
+	}
+
+	days := int(d.Sub(cal.yearStart(y)).Hours() / 24)
+	w := days / 7 // 0-based
+
+	p := 1
+	for p < 12 && w >= cal.periodOffset(p+1) {
+		p++
+	}
+	return RetailDate{y, (p-1)/3 + 1, p, w - cal.periodOffset(p) + 1, w + 1, days%7 + 1}
+}
+
+// range of the retail year
+func (cal *RetailCalendar) YearRange(y int, loc *time.Location) Range {
+	return newRetailRange(cal.yearStart(y), cal.Weeks(y)*7, loc)
+}
+
+// range of the retail quarter
+func (cal *RetailCalendar) QuarterRange(y int, q int, loc *time.Location) Range {
+	start := cal.PeriodRange(y, q*3-2, loc).Start
+	return Range{start, cal.PeriodRange(y, q*3, loc).End}
+}
+
+// range of the retail period (the 53rd week belongs to the 12th period)
+func (cal *RetailCalendar) PeriodRange(y int, p int, loc *time.Location) Range {
+	weeks := cal.periodWeeks(p)
+	if p == 12 && cal.Weeks(y) == 53 {
+		weeks++
+	}
+	return newRetailRange(cal.yearStart(y).AddDate(0, 0, cal.periodOffset(p)*7), weeks*7, loc)
+}
+
+// range of days from start (00:00 UTC) in loc
+func newRetailRange(start time.Time, days int, loc *time.Location) Range {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	return Range{start, start.AddDate(0, 0, days)}
+}
+
+// Parse a retail date ("FY2022 P03 W2", "FY2022 Q1", "FY2022 W10")
+// and return the range of the week, period, quarter or year
+// (weeks are counted from the start of the period, quarter or year)
+func (cal *RetailCalendar) ParseRange(s string, loc *time.Location) (Range, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	s = strings.TrimSpace(s)
+	s_len := len(s)
+
+	y, q, p, pos := scanFiscalYear(s, 0)
+	if pos < 0 {
+		return Range{}, errors.New(fmt.Sprintf("failed to parse retail date: %s", s))
+	}
+
+	// W2
+	w := -1
+	if pos+1 < s_len && (s[pos] == ' ' || s[pos] == '-') {
+		pos_ := pos + 1
+		if pos_ < s_len && (s[pos_] == 'w' || s[pos_] == 'W') {
+			pos_++
+			n, ok := parseInt(&s, &pos_, 1, 2)
+			if !ok {
+				return Range{}, errors.New(fmt.Sprintf("failed to parse retail date: %s", s))
+			}
+			w, pos = n, pos_
+		}
+	}
+	if pos != s_len {
+		return Range{}, errors.New(fmt.Sprintf("failed to parse retail date: %s", s))
+	}
+
+	r := cal.YearRange(y, loc)
+	if p > 0 {
+		r = cal.PeriodRange(y, p, loc)
+	} else if q > 0 {
+		r = cal.QuarterRange(y, q, loc)
+	}
+	if w < 0 {
+		return r, nil
+	}
+
+	// week
+	if weeks := int(r.Duration().Hours()+12) / 24 / 7; w < 1 || weeks < w {
+		return Range{}, outOfRange("week", w)
+	}
+	start := r.Start.AddDate(0, 0, (w-1)*7)
+	return Range{start, start.AddDate(0, 0, 7)}, nil
+}
+
+// same as ParseRange but returns the first day
+func (cal *RetailCalendar) Parse(s string, loc *time.Location) (*TimeData, error) {
+	r, err := cal.ParseRange(s, loc)
+	if err != nil {
+		return nil, err
+	}
+	data := newTimeData()
+	data.setFromTime(&r.Start)
+	return data, nil
+}
+
+// ============================================================
+// TimeData
+// ============================================================
+
+// date in the retail calendar
+func (data *TimeData) GetRetailDate(cal *RetailCalendar) RetailDate {
+	return cal.Date(data)
+}
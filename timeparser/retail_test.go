@@ -0,0 +1,108 @@
+package timeparser
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetailCalendarYears(t *testing.T) {
+	nrf := &NRFCalendar
+	last := &RetailCalendar{RETAIL_445, RETAIL_LAST_SATURDAY, 1}
+
+	testcases := []struct {
+		cal   *RetailCalendar
+		year  int
+		start string
+		end   string
+		weeks int
+	}{
+		// NRF
+		{nrf, 2017, "2017-01-29", "2018-02-03", 53},
+		{nrf, 2021, "2021-01-31", "2022-01-29", 52},
+		{nrf, 2022, "2022-01-30", "2023-01-28", 52},
+		{nrf, 2023, "2023-01-29", "2024-02-03", 53},
+		// last Saturday of January
+		{last, 2020, "2020-01-26", "2021-01-30", 53},
+		{last, 2023, "2023-01-29", "2024-01-27", 52},
+	}
+	for _, tc := range testcases {
+		r := tc.cal.YearRange(tc.year, time.UTC)
+		assert.Equal(t, tc.start, FormatTime("Y-m-d", &r.Start), tc.year)
+		end := r.EndInclusive()
+		assert.Equal(t, tc.end, FormatTime("Y-m-d", &end), tc.year)
+		assert.Equal(t, tc.weeks, tc.cal.Weeks(tc.year), tc.year)
+	}
+
+	// periods
+	r := nrf.PeriodRange(2022, 2, time.UTC)
+	assert.Equal(t, time.Date(2022, 2, 27, 0, 0, 0, 0, time.UTC), r.Start)
+	assert.Equal(t, 5*7*24*time.Hour, r.Duration())
+
+	// the 53rd week is in the last period
+	r = nrf.PeriodRange(2023, 12, time.UTC)
+	assert.Equal(t, 5*7*24*time.Hour, r.Duration())
+	r = nrf.QuarterRange(2023, 4, time.UTC)
+	assert.Equal(t, 14*7*24*time.Hour, r.Duration())
+}
+
+func TestRetailCalendarDate(t *testing.T) {
+	nrf := &NRFCalendar
+	testcases := map[string]RetailDate{
+		"2022-01-29": {2021, 4, 12, 4, 52, 7},
+		"2022-01-30": {2022, 1, 1, 1, 1, 1},
+		"2022-04-30": {2022, 1, 3, 4, 13, 7},
+		"2022-05-01": {2022, 2, 4, 1, 14, 1},
+		"2024-02-03": {2023, 4, 12, 5, 53, 7},
+	}
+	for date, expected := range testcases {
+		data, err := New(date)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, data.GetRetailDate(nrf), date)
+	}
+
+	// 4-4-5
+	data, _ := New("2022-04-30")
+	assert.Equal(t, 3, (&RetailCalendar{RETAIL_445, RETAIL_NEAREST_SATURDAY, 1}).Date(data).Period)
+	assert.Equal(t, 5, (&RetailCalendar{RETAIL_445, RETAIL_NEAREST_SATURDAY, 1}).Date(data).Week)
+}
+
+func TestRetailCalendarParse(t *testing.T) {
+	nrf := &NRFCalendar
+	testcases := map[string][2]string{
+		"FY2022 P03 W2": {"2022-04-10", "2022-04-16"},
+		"FY22 P03":      {"2022-04-03", "2022-04-30"},
+		"FY2022 Q2":     {"2022-05-01", "2022-07-30"},
+		"FY2022 W10":    {"2022-04-03", "2022-04-09"},
+		"FY2023 P12 W5": {"2024-01-28", "2024-02-03"},
+		"FY2022":        {"2022-01-30", "2023-01-28"},
+	}
+	for s, expected := range testcases {
+		r, err := nrf.ParseRange(s, time.UTC)
+		assert.Nil(t, err, s)
+		end := r.EndInclusive()
+		assert.Equal(t, expected[0], FormatTime("Y-m-d", &r.Start), s)
+		assert.Equal(t, expected[1], FormatTime("Y-m-d", &end), s)
+	}
+
+	data, err := nrf.Parse("FY2022 P03 W2", time.UTC)
+	assert.Nil(t, err)
+	assert.Equal(t, "2022-04-10", data.Format("Y-m-d"))
+
+	// errors
+	for _, s := range []string{"FY2022 P12 W5", "FY2022 W0", "FY2022 W54", "FY2022 X", "2022 P03"} {
+		_, err = nrf.ParseRange(s, time.UTC)
+		assert.NotNil(t, err, s)
+	}
+	_, err = nrf.ParseRange("FY2022 P12 W5", time.UTC)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+}
+
+func ExampleRetailCalendar() {
+	r, _ := NRFCalendar.ParseRange("FY2022 P03 W2", time.UTC)
+	fmt.Println(FormatTime("Y-m-d", &r.Start)) // 2022-04-10
+
+	data, _ := New("2022-05-01")
+	fmt.Printf("%+v\n", data.GetRetailDate(&NRFCalendar)) // {Year:2022 Quarter:2 Period:4 Week:1 WeekOfYear:14 Weekday:1}
+}
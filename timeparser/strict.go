@@ -0,0 +1,106 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ==============================================================
+// Strict Mode
+// ==============================================================
+
+// ErrOutOfRange is returned (wrapped) by strict functions
+// when a date or time field overflows instead of being normalized
+var ErrOutOfRange = errors.New("out of range")
+
+func outOfRange(field string, v int) error {
+	return fmt.Errorf("%w: %s %d", ErrOutOfRange, field, v)
+}
+
+// check specified fields before they are normalized
+func (data *TimeData) checkRange() error {
+	if data.hasFlag(SET_MONTH) && (data.m < 1 || 12 < data.m) {
+		return outOfRange("month", data.m)
+	}
+	if (data.hasFlag(SET_MONTH) || data.hasFlag(SET_DAY)) && !checkDate(data.y, data.m, data.d) {
+		return fmt.Errorf("%w: day %d of %04d-%02d", ErrOutOfRange, data.d, data.y, data.m)
+	}
+	if !data.HasTime() {
+		return nil
+	}
+	if data.h < 0 || 23 < data.h {
+		return outOfRange("hour", data.h)
+	}
+	if data.i < 0 || 59 < data.i {
+		return outOfRange("minute", data.i)
+	}
+	if data.s < 0 || 59 < data.s {
+		return outOfRange("second", data.s)
+	}
+	if data.ns < 0 || 999999999 < data.ns {
+		return outOfRange("nanosecond", data.ns)
+	}
+	return nil
+}
+
+// Strict setters don't normalize values.
+// They return ErrOutOfRange and leave data unchanged if a value overflows.
+
+func (data *TimeData) SetDateStrict(y int, m int, d int) error {
+	if m < 1 || 12 < m {
+		return outOfRange("month", m)
+	}
+	if d < 1 || getLastDay(y, m) < d {
+		return fmt.Errorf("%w: day %d of %04d-%02d", ErrOutOfRange, d, y, m)
+	}
+	data.setYear(y)
+	data.setMonth(m)
+	data.setDay(d)
+	return nil
+}
+func (data *TimeData) SetTimeStrict(h int, i int, s int, ns int) error {
+	if h < 0 || 23 < h {
+		return outOfRange("hour", h)
+	}
+	if i < 0 || 59 < i {
+		return outOfRange("minute", i)
+	}
+	if s < 0 || 59 < s {
+		return outOfRange("second", s)
+	}
+	if ns < 0 || 999999999 < ns {
+		return outOfRange("nanosecond", ns)
+	}
+	data.setTime(h, i, s, ns)
+	return nil
+}
+func (data *TimeData) SetMonthStrict(m int) error {
+	return data.SetDateStrict(data.y, m, data.d)
+}
+func (data *TimeData) SetDayStrict(d int) error {
+	return data.SetDateStrict(data.y, data.m, d)
+}
+func (data *TimeData) SetHourStrict(h int) error {
+	return data.SetTimeStrict(h, data.i, data.s, data.ns)
+}
+func (data *TimeData) SetMinuteStrict(i int) error {
+	return data.SetTimeStrict(data.h, i, data.s, data.ns)
+}
+func (data *TimeData) SetSecondStrict(s int) error {
+	return data.SetTimeStrict(data.h, data.i, s, data.ns)
+}
+func (data *TimeData) SetMillisecondStrict(ms int) error {
+	if ms < 0 || 999 < ms {
+		return outOfRange("millisecond", ms)
+	}
+	return data.SetTimeStrict(data.h, data.i, data.s, ms*1e6)
+}
+func (data *TimeData) SetMicrosecondStrict(us int) error {
+	if us < 0 || 999999 < us {
+		return outOfRange("microsecond", us)
+	}
+	return data.SetTimeStrict(data.h, data.i, data.s, us*1e3)
+}
+func (data *TimeData) SetNanosecondStrict(ns int) error {
+	return data.SetTimeStrict(data.h, data.i, data.s, ns)
+}
@@ -0,0 +1,100 @@
+package timeparser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseFormatStrict(t *testing.T) {
+	tm, err := ParseFormatStrict("Y-m-d H:i:s", "2021-12-29 00:00:00")
+	assert.Nil(t, err)
+	assert.Equal(t, 29, tm.Day())
+	assert.Equal(t, 0, tm.Hour())
+
+	tm, err = ParseFormatStrict("Y-m-d", "2024-02-29")
+	assert.Nil(t, err)
+	assert.Equal(t, 29, tm.Day())
+
+	for format, s := range map[string]string{
+		"Y-m-d":  "2021-02-30",
+		"Y-m-j":  "2021-04-31",
+		"!Y-m-d": "2021-12-00",
+	} {
+		_, err := ParseFormatStrict(format, s)
+		assert.True(t, errors.Is(err, ErrOutOfRange), s)
+	}
+	_, err = ParseFormatStrict("Y-m-d H", "2021-12-29 24")
+	assert.NotNil(t, err)
+
+	// lenient
+	tm, err = ParseFormat("Y-m-d", "2021-02-30")
+	assert.Nil(t, err)
+	assert.Equal(t, time.March, tm.Month())
+	assert.Equal(t, 2, tm.Day())
+
+	// 0 is a valid hour, minute and second
+	tm, err = ParseFormat("H:i:s", "00:00:00")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, tm.Hour())
+	tm, err = ParseFormat("Y z", "2021 0")
+	assert.Nil(t, err)
+	assert.Equal(t, time.January, tm.Month())
+	assert.Equal(t, 1, tm.Day())
+}
+
+func TestParseTimeStrStrict(t *testing.T) {
+	base := time.Date(2000, time.September, 10, 0, 0, 0, 0, time.Local)
+
+	tm, err := ParseTimeStrStrict("28 February 2021 +1 day", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, time.March, tm.Month())
+	assert.Equal(t, 1, tm.Day())
+
+	_, err = ParseTimeStrStrict("30 February 2021", &base)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.Equal(t, "out of range: day 30 of 2021-02", err.Error())
+
+	// lenient
+	tm, err = ParseTimeStr("30 February 2021", &base)
+	assert.Nil(t, err)
+	assert.Equal(t, time.March, tm.Month())
+}
+
+func TestSetStrict(t *testing.T) {
+	tdata, err := New("2021-01-31 18:24:36")
+	assert.Nil(t, err)
+
+	assert.True(t, errors.Is(tdata.SetMonthStrict(2), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetMonthStrict(13), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetDayStrict(32), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetHourStrict(24), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetMinuteStrict(-1), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetSecondStrict(60), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetMillisecondStrict(1000), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetMicrosecondStrict(1e6), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetNanosecondStrict(1e9), ErrOutOfRange))
+	assert.True(t, errors.Is(tdata.SetDateStrict(2021, 2, 29), ErrOutOfRange))
+
+	// unchanged
+	assert.Equal(t, "2021-01-31 18:24:36", tdata.Format("Y-m-d H:i:s"))
+
+	assert.Nil(t, tdata.SetDateStrict(2024, 2, 29))
+	assert.Nil(t, tdata.SetTimeStrict(0, 0, 0, 0))
+	assert.Nil(t, tdata.SetMonthStrict(3))
+	assert.Nil(t, tdata.SetMillisecondStrict(123))
+	assert.Equal(t, "2024-03-29 00:00:00.123", tdata.Format("Y-m-d H:i:s.v"))
+
+	// lenient setters normalize values
+	tdata.SetDay(40)
+	assert.Equal(t, "2024-04-09", tdata.Format("Y-m-d"))
+}
+
+func ExampleParseFormatStrict() {
+	_, err := ParseFormatStrict("Y-m-d", "2021-02-30")
+	if errors.Is(err, ErrOutOfRange) {
+		fmt.Println(err) // out of range: day 30 of 2021-02
+	}
+}
@@ -1,8 +1,8 @@
 package timeparser
 
-//import (
-//	"strings"
-//)
+import (
+	"time"
+)
 
 // ==============================================================
 // get*Table
@@ -28,7 +28,7 @@ func getMonthTable() map[string]int {
 		//"aug":       8,
 		"september": 9,
 		//"sep":       9,
-		"octobar": 10,
+		"october": 10,
 		//"oct":       10,
 		"november": 11,
 		//"nov":       11,
@@ -63,6 +63,9 @@ func isSeparator(c byte) bool {
 func isNumeric(c byte) bool {
 	return '0' <= c && c <= '9'
 }
+func isAlpha(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
 func isAlphanumeric(c byte) bool {
 	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
 }
@@ -118,6 +121,21 @@ func skipChars(s *string, pos_s *int, callback func(c byte) bool) int {
 // others
 // ==============================================================
 
+// English ordinal suffix of a number (st, nd, rd, th)
+func ordinalSuffix(n int) string {
+	if n%100 < 11 || 13 < n%100 {
+		switch n % 10 {
+		case 1:
+			return "st"
+		case 2:
+			return "nd"
+		case 3:
+			return "rd"
+		}
+	}
+	return "th"
+}
+
 // check if ymd is correct
 func checkDate(y int, m int, d int) bool {
 	if y < 0 || (m < 1 || 12 < m) || (d < 1 || 31 < d) {
@@ -196,3 +214,13 @@ func startsWithWeekdayName(s string) (int, string) {
 	}
 	return -1, ""
 }
+
+// get the date of an ISO 8601 week date (d: 1=Monday ... 7=Sunday)
+func isoWeekDate(y int, w int, d int) (int, int, int) {
+	// the week 1 always contains January 4th
+	jan4 := time.Date(y, time.January, 4, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan4.Weekday()) + 6) % 7
+
+	t := jan4.AddDate(0, 0, (w-1)*7+(d-1)-offset)
+	return t.Year(), int(t.Month()), t.Day()
+}